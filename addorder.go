@@ -27,6 +27,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type OrderSide string
@@ -38,22 +41,182 @@ type AddOrderOrderType string
 
 const OrderTypeLimit AddOrderOrderType = "limit"
 const OrderTypeMarket AddOrderOrderType = "market"
+const OrderTypeStopLoss AddOrderOrderType = "stop-loss"
+const OrderTypeTakeProfit AddOrderOrderType = "take-profit"
+const OrderTypeStopLossLimit AddOrderOrderType = "stop-loss-limit"
+const OrderTypeTakeProfitLimit AddOrderOrderType = "take-profit-limit"
+const OrderTypeTrailingStop AddOrderOrderType = "trailing-stop"
+const OrderTypeTrailingStopLimit AddOrderOrderType = "trailing-stop-limit"
+const OrderTypeSettlePosition AddOrderOrderType = "settle-position"
+
+// PriceOffset makes a price relative to the last traded price.
+type PriceOffset string
+
+const PriceAbsolute PriceOffset = ""
+const PriceOffsetPlus PriceOffset = "+"
+const PriceOffsetMinus PriceOffset = "-"
+
+// PriceOffsetSide adds the offset for buy orders and subtracts it for sell
+// orders.
+const PriceOffsetSide PriceOffset = "#"
+
+// Trigger is the price that triggers a stop or take-profit order.
+type Trigger string
+
+const TriggerLast Trigger = "last"
+const TriggerIndex Trigger = "index"
+
+type OrderFlag string
+
+const OrderFlagPost OrderFlag = "post"
+const OrderFlagFeeInBase OrderFlag = "fcib"
+const OrderFlagFeeInQuote OrderFlag = "fciq"
+const OrderFlagNoMarketPriceProtection OrderFlag = "nompp"
+const OrderFlagVolumeInQuote OrderFlag = "viqc"
+
+type TimeInForce string
+
+const TimeInForceGTC TimeInForce = "GTC"
+const TimeInForceIOC TimeInForce = "IOC"
+const TimeInForceGTD TimeInForce = "GTD"
+
+// OrderTime is a scheduled start or expire time, either absolute or
+// relative to when the order is received. The zero value means now for a
+// start time and never for an expire time.
+type OrderTime struct {
+	at    time.Time
+	after time.Duration
+}
+
+// OrderTimeAt returns an OrderTime at an absolute time.
+func OrderTimeAt(t time.Time) OrderTime {
+	return OrderTime{at: t}
+}
+
+// OrderTimeAfter returns an OrderTime relative to when the order is
+// received. Kraken only supports whole seconds, so the duration is rounded
+// up to the next second.
+func OrderTimeAfter(d time.Duration) OrderTime {
+	if d > 0 && d%time.Second != 0 {
+		d = d - d%time.Second + time.Second
+	}
+	return OrderTime{after: d}
+}
+
+func (t OrderTime) IsZero() bool {
+	return t.at.IsZero() && t.after == 0
+}
+
+// resolve returns the absolute time for an order received at now.
+func (t OrderTime) resolve(now time.Time) time.Time {
+	if !t.at.IsZero() {
+		return t.at
+	}
+	return now.Add(t.after)
+}
+
+func (t OrderTime) String() string {
+	if !t.at.IsZero() {
+		return strconv.FormatInt(t.at.Unix(), 10)
+	}
+	if t.after > 0 {
+		return fmt.Sprintf("+%d", int64(t.after/time.Second))
+	}
+	return "0"
+}
 
 type AddOrderRequest struct {
-	Pair         string
-	Side         OrderSide
-	Type         AddOrderOrderType
-	Price        float64
-	Volume       float64
+	Pair   string
+	Side   OrderSide
+	Type   AddOrderOrderType
+	Volume Decimal
+
+	// Price is the limit price of limit orders and the trigger price of
+	// stop, take-profit and trailing stop orders. It must not be set for
+	// market and settle-position orders.
+	Price        Decimal
+	PriceOffset  PriceOffset
+	PricePercent bool
+
+	// Price2 is the limit price of stop-loss-limit, take-profit-limit
+	// and trailing-stop-limit orders.
+	Price2        Decimal
+	Price2Offset  PriceOffset
+	Price2Percent bool
+
+	// Trigger for stop, take-profit and trailing stop orders, defaulting
+	// to last.
+	Trigger Trigger
+
+	Flags       []OrderFlag
+	TimeInForce TimeInForce
+	StartTime   OrderTime
+	ExpireTime  OrderTime
+
+	// Leverage is the amount of leverage desired, zero for a spot order.
+	Leverage int
+
+	// ReduceOnly only allows a margin order to reduce an open position.
+	ReduceOnly bool
+
+	// Deadline after which the order is rejected by the matching engine
+	// instead of being placed, at most 60 seconds in the future.
+	Deadline time.Time
+
+	// Close is an optional conditional close order placed when this
+	// order is filled.
+	Close *CloseOrder
+
 	UserRef      int32
 	ValidateOnly bool
 }
 
+// CloseOrder is a conditional close order attached to an AddOrderRequest.
+// Its side is the opposite of the opening order, and its volume the
+// filled volume.
+type CloseOrder struct {
+	Type AddOrderOrderType
+
+	Price        Decimal
+	PriceOffset  PriceOffset
+	PricePercent bool
+
+	Price2        Decimal
+	Price2Offset  PriceOffset
+	Price2Percent bool
+}
+
+func (c CloseOrder) validate() error {
+	switch c.Type {
+	case OrderTypeLimit, OrderTypeStopLoss, OrderTypeTakeProfit,
+		OrderTypeStopLossLimit, OrderTypeTakeProfitLimit,
+		OrderTypeTrailingStop, OrderTypeTrailingStopLimit:
+	default:
+		return fmt.Errorf("invalid order type: %s", c.Type)
+	}
+	return c.order().validatePrices()
+}
+
+// order returns the close order as an AddOrderRequest for validating its
+// prices.
+func (c CloseOrder) order() AddOrderRequest {
+	return AddOrderRequest{
+		Type:          c.Type,
+		Price:         c.Price,
+		PriceOffset:   c.PriceOffset,
+		PricePercent:  c.PricePercent,
+		Price2:        c.Price2,
+		Price2Offset:  c.Price2Offset,
+		Price2Percent: c.Price2Percent,
+	}
+}
+
 type AddOrderResponse struct {
 	Error  []interface{} `json:"error"`
 	Result struct {
 		Descr struct {
 			Order string `json:"order"`
+			Close string `json:"close"`
 		}
 		Txid []string `json:"txid"`
 	} `json:"result"`
@@ -63,21 +226,301 @@ func (r *AddOrderResponse) HasError() bool {
 	return len(r.Error) > 0
 }
 
-func (c *RestClient) AddOrder(order AddOrderRequest) (*AddOrderResponse, error) {
+// hasFlag returns true if the order has the order flag set.
+func (r AddOrderRequest) hasFlag(flag OrderFlag) bool {
+	for _, f := range r.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// usesPrice returns true if the order type takes a price.
+func (t AddOrderOrderType) usesPrice() bool {
+	return t != OrderTypeMarket && t != OrderTypeSettlePosition
+}
+
+// usesPrice2 returns true if the order type takes a secondary price.
+func (t AddOrderOrderType) usesPrice2() bool {
+	switch t {
+	case OrderTypeStopLossLimit, OrderTypeTakeProfitLimit, OrderTypeTrailingStopLimit:
+		return true
+	}
+	return false
+}
+
+// usesTrigger returns true if the order type is triggered by a price.
+func (t AddOrderOrderType) usesTrigger() bool {
+	return t.usesPrice() && t != OrderTypeLimit
+}
+
+// Validate checks that the fields required by the order type are set, and
+// that no fields are set that the order type does not use.
+func (r AddOrderRequest) Validate() error {
+	if r.Pair == "" {
+		return fmt.Errorf("pair is required")
+	}
+	if r.Side != OrderSideBuy && r.Side != OrderSideSell {
+		return fmt.Errorf("invalid order side: %s", r.Side)
+	}
+	if r.Volume.Sign() < 0 || (r.Volume.IsZero() && r.Type != OrderTypeSettlePosition) {
+		return fmt.Errorf("invalid volume: %v", r.Volume)
+	}
+
+	switch r.Type {
+	case OrderTypeLimit, OrderTypeMarket, OrderTypeStopLoss,
+		OrderTypeTakeProfit, OrderTypeStopLossLimit,
+		OrderTypeTakeProfitLimit, OrderTypeTrailingStop,
+		OrderTypeTrailingStopLimit, OrderTypeSettlePosition:
+	default:
+		return fmt.Errorf("invalid order type: %s", r.Type)
+	}
+
+	if err := r.validatePrices(); err != nil {
+		return err
+	}
+
+	if r.Trigger != "" {
+		if !r.Type.usesTrigger() {
+			return fmt.Errorf("%s order does not take a trigger", r.Type)
+		}
+		if r.Trigger != TriggerLast && r.Trigger != TriggerIndex {
+			return fmt.Errorf("invalid trigger: %s", r.Trigger)
+		}
+	}
+
+	if r.Close != nil {
+		if err := r.Close.validate(); err != nil {
+			return fmt.Errorf("close: %v", err)
+		}
+	}
+
+	return r.validateExecution()
+}
+
+// validatePrices checks the prices and offsets against the order type.
+func (r AddOrderRequest) validatePrices() error {
+	if r.Type.usesPrice() {
+		if r.Price.Sign() <= 0 {
+			return fmt.Errorf("%s order requires a price", r.Type)
+		}
+		if err := validatePriceOffset(r.PriceOffset, r.PricePercent); err != nil {
+			return err
+		}
+	} else if !r.Price.IsZero() || r.PriceOffset != PriceAbsolute || r.PricePercent {
+		return fmt.Errorf("%s order does not take a price", r.Type)
+	}
+
+	if r.Type.usesPrice2() {
+		if r.Price2.Sign() <= 0 {
+			return fmt.Errorf("%s order requires price2", r.Type)
+		}
+		if err := validatePriceOffset(r.Price2Offset, r.Price2Percent); err != nil {
+			return err
+		}
+	} else if !r.Price2.IsZero() || r.Price2Offset != PriceAbsolute || r.Price2Percent {
+		return fmt.Errorf("%s order does not take price2", r.Type)
+	}
+
+	// Trailing stops are always relative to the market; the trigger
+	// offset must be positive and the limit offset signed.
+	if r.Type == OrderTypeTrailingStop || r.Type == OrderTypeTrailingStopLimit {
+		if r.PriceOffset != PriceOffsetPlus {
+			return fmt.Errorf("%s order requires a + price offset", r.Type)
+		}
+	}
+	if r.Type == OrderTypeTrailingStopLimit {
+		if r.Price2Offset != PriceOffsetPlus && r.Price2Offset != PriceOffsetMinus {
+			return fmt.Errorf("%s order requires a + or - price2 offset", r.Type)
+		}
+	}
+
+	return nil
+}
+
+// maxDeadline is the furthest in the future an order deadline may be.
+const maxDeadline = 60 * time.Second
+
+// validateExecution checks the order flags, time in force, scheduling and
+// leverage for unsupported values and incompatible combinations.
+func (r AddOrderRequest) validateExecution() error {
+	flags := map[OrderFlag]bool{}
+	for _, flag := range r.Flags {
+		switch flag {
+		case OrderFlagPost, OrderFlagFeeInBase, OrderFlagFeeInQuote,
+			OrderFlagNoMarketPriceProtection, OrderFlagVolumeInQuote:
+		default:
+			return fmt.Errorf("invalid order flag: %s", flag)
+		}
+		if flags[flag] {
+			return fmt.Errorf("duplicate order flag: %s", flag)
+		}
+		flags[flag] = true
+	}
+
+	if flags[OrderFlagPost] && r.Type != OrderTypeLimit {
+		return fmt.Errorf("post only requires a limit order")
+	}
+	if flags[OrderFlagFeeInBase] && flags[OrderFlagFeeInQuote] {
+		return fmt.Errorf("fees cannot be in both base and quote currency")
+	}
+	if flags[OrderFlagVolumeInQuote] {
+		if r.Type != OrderTypeMarket {
+			return fmt.Errorf("volume in quote currency requires a market order")
+		}
+		if r.Leverage > 0 {
+			return fmt.Errorf("volume in quote currency is not available for leveraged orders")
+		}
+	}
+
+	switch r.TimeInForce {
+	case "", TimeInForceGTC:
+		if !r.ExpireTime.IsZero() {
+			return fmt.Errorf("expire time requires GTD time in force")
+		}
+	case TimeInForceIOC:
+		if flags[OrderFlagPost] {
+			return fmt.Errorf("post only orders cannot be IOC")
+		}
+		if !r.ExpireTime.IsZero() {
+			return fmt.Errorf("expire time requires GTD time in force")
+		}
+	case TimeInForceGTD:
+		if r.ExpireTime.IsZero() {
+			return fmt.Errorf("GTD time in force requires an expire time")
+		}
+	default:
+		return fmt.Errorf("invalid time in force: %s", r.TimeInForce)
+	}
+	if r.TimeInForce != "" && r.Type == OrderTypeMarket {
+		return fmt.Errorf("market orders do not take a time in force")
+	}
+
+	if r.StartTime.after < 0 || r.ExpireTime.after < 0 {
+		return fmt.Errorf("relative order times must be positive")
+	}
+	now := time.Now()
+	if !r.ExpireTime.IsZero() &&
+		!r.ExpireTime.resolve(now).After(r.StartTime.resolve(now)) {
+		return fmt.Errorf("expire time must be after start time")
+	}
+
+	if !r.Deadline.IsZero() {
+		if !r.Deadline.After(now) {
+			return fmt.Errorf("deadline must be in the future")
+		}
+		if r.Deadline.Sub(now) > maxDeadline {
+			return fmt.Errorf("deadline must be within %v", maxDeadline)
+		}
+	}
+
+	if r.Leverage < 0 {
+		return fmt.Errorf("invalid leverage: %d", r.Leverage)
+	}
+	if r.ReduceOnly && r.Leverage == 0 {
+		return fmt.Errorf("reduce only requires a leveraged order")
+	}
+
+	return nil
+}
+
+func validatePriceOffset(offset PriceOffset, percent bool) error {
+	switch offset {
+	case PriceAbsolute:
+		if percent {
+			return fmt.Errorf("a percentage price requires an offset")
+		}
+	case PriceOffsetPlus, PriceOffsetMinus, PriceOffsetSide:
+	default:
+		return fmt.Errorf("invalid price offset: %s", offset)
+	}
+	return nil
+}
+
+// maxDecimals is the most decimals a price or volume is sent with.
+const maxDecimals = 8
+
+// formatPrice formats a price with its offset prefix and percentage
+// suffix.
+func formatPrice(price Decimal, offset PriceOffset, percent bool) string {
+	if percent {
+		return fmt.Sprintf("%s%s%%", offset, price)
+	}
+	return fmt.Sprintf("%s%s", offset, price.Round(maxDecimals))
+}
+
+// params returns the order parameters common to the REST and websocket
+// APIs.
+func (r AddOrderRequest) params() map[string]interface{} {
 	params := map[string]interface{}{}
-	params["pair"] = order.Pair
-	params["type"] = order.Side
-	params["ordertype"] = order.Type
-	params["price"] = fmt.Sprintf("%.8f", order.Price)
-	params["volume"] = fmt.Sprintf("%.8f", order.Volume)
-	if order.UserRef > 0 {
-		params["userref"] = order.UserRef
-	}
-	if order.ValidateOnly {
+	params["pair"] = r.Pair
+	params["type"] = r.Side
+	params["ordertype"] = r.Type
+	if r.Type.usesPrice() {
+		params["price"] = formatPrice(r.Price, r.PriceOffset, r.PricePercent)
+	}
+	if r.Type.usesPrice2() {
+		params["price2"] = formatPrice(r.Price2, r.Price2Offset, r.Price2Percent)
+	}
+	if r.Trigger != "" {
+		params["trigger"] = r.Trigger
+	}
+	params["volume"] = r.Volume.Round(maxDecimals).String()
+	if len(r.Flags) > 0 {
+		flags := make([]string, len(r.Flags))
+		for i, flag := range r.Flags {
+			flags[i] = string(flag)
+		}
+		params["oflags"] = strings.Join(flags, ",")
+	}
+	if r.TimeInForce != "" {
+		params["timeinforce"] = r.TimeInForce
+	}
+	if !r.StartTime.IsZero() {
+		params["starttm"] = r.StartTime.String()
+	}
+	if !r.ExpireTime.IsZero() {
+		params["expiretm"] = r.ExpireTime.String()
+	}
+	if r.Leverage > 0 {
+		params["leverage"] = strconv.Itoa(r.Leverage)
+	}
+	if r.ReduceOnly {
+		params["reduce_only"] = true
+	}
+	if !r.Deadline.IsZero() {
+		params["deadline"] = r.Deadline.UTC().Format(time.RFC3339Nano)
+	}
+	if r.Close != nil {
+		c := r.Close
+		params["close[ordertype]"] = c.Type
+		if c.Type.usesPrice() {
+			params["close[price]"] = formatPrice(c.Price, c.PriceOffset, c.PricePercent)
+		}
+		if c.Type.usesPrice2() {
+			params["close[price2]"] = formatPrice(c.Price2, c.Price2Offset, c.Price2Percent)
+		}
+	}
+	if r.UserRef > 0 {
+		params["userref"] = r.UserRef
+	}
+	if r.ValidateOnly {
 		params["validate"] = "1"
 	}
+	return params
+}
+
+func (c *RestClient) AddOrder(order AddOrderRequest) (*AddOrderResponse, error) {
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+	order, err := c.orderRounding().round(order)
+	if err != nil {
+		return nil, err
+	}
 
-	httpResponse, err := c.Post("/0/private/AddOrder", params)
+	httpResponse, err := c.Post("/0/private/AddOrder", order.params())
 	if err != nil {
 		return nil, err
 	}
@@ -25,13 +25,18 @@ package krakenapi
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"strings"
+	"sync"
 )
 
 type AssetPairService struct {
 	cacheFilename string
-	pairs         map[string]*AssetPairInfo
+
+	// Pairs by REST, alternate and websocket name, guarded by lock.
+	lock  sync.RWMutex
+	pairs map[string]*AssetPairInfo
 }
 
 func NewAssetPairService() *AssetPairService {
@@ -42,6 +47,8 @@ func (s *AssetPairService) SetCacheFilename(filename string) {
 	s.cacheFilename = filename
 }
 
+// Refresh loads the asset pairs from the API, leaving the loaded pairs
+// unchanged if the request fails.
 func (s *AssetPairService) Refresh() error {
 	client, err := NewRestClient("", "")
 	if err != nil {
@@ -49,18 +56,31 @@ func (s *AssetPairService) Refresh() error {
 	}
 	response, err := client.Get("/0/public/AssetPairs")
 	if err != nil {
-		return err
+		return RequestError{
+			NetworkError: err,
+		}
+	}
+	defer response.Body.Close()
+	if response.StatusCode != 200 {
+		return RequestError{
+			HttpError: fmt.Errorf("%s", response.Status),
+		}
 	}
 	body, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return err
-	}
-	if s.cacheFilename != "" {
-		ioutil.WriteFile(s.cacheFilename, body, 0644)
+		return RequestError{
+			NetworkError: err,
+		}
 	}
 	var assetPairResponse AssetPairResponse
 	if err := json.Unmarshal(body, &assetPairResponse); err != nil {
-		return err
+		return decodeError(err)
+	}
+	if assetPairResponse.HasError() {
+		return fmt.Errorf("failed to get asset pairs: %v", assetPairResponse.Error)
+	}
+	if s.cacheFilename != "" {
+		ioutil.WriteFile(s.cacheFilename, body, 0644)
 	}
 	s.LoadResponse(assetPairResponse)
 	return nil
@@ -79,32 +99,135 @@ func (s *AssetPairService) LoadResponse(response AssetPairResponse) {
 		response.Result[altname] = info
 		response.Result[wsname] = info
 	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
 	s.pairs = response.Result
 }
 
 func (s *AssetPairService) GetRestPair(pair string) string {
-	info, ok := s.pairs[strings.ToUpper(pair)]
-	if ok {
+	info := s.GetInfo(pair)
+	if info != nil {
 		return info.Pair
 	}
 	return ""
 }
 
 func (s *AssetPairService) GetWsPair(pair string) string {
-	info, ok := s.pairs[strings.ToUpper(pair)]
-	if ok {
+	info := s.GetInfo(pair)
+	if info != nil {
 		return info.WsName
 	}
 	return ""
 }
 
+// GetInfo returns the info for a pair given by its REST, alternate or
+// websocket name, or nil if unknown.
+func (s *AssetPairService) GetInfo(pair string) *AssetPairInfo {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.pairs[strings.ToUpper(pair)]
+}
+
+// PriceDecimals returns the number of decimals allowed in a price.
+func (s *AssetPairService) PriceDecimals(pair string) (int, bool) {
+	info := s.GetInfo(pair)
+	if info == nil {
+		return 0, false
+	}
+	return info.PairDecimals, true
+}
+
+// LotDecimals returns the number of decimals allowed in a volume.
+func (s *AssetPairService) LotDecimals(pair string) (int, bool) {
+	info := s.GetInfo(pair)
+	if info == nil {
+		return 0, false
+	}
+	return info.LotDecimals, true
+}
+
+// MinOrder returns the minimum order volume in the base currency.
+func (s *AssetPairService) MinOrder(pair string) (Decimal, bool) {
+	info := s.GetInfo(pair)
+	if info == nil {
+		return Decimal{}, false
+	}
+	return info.OrderMin, true
+}
+
+// Fees returns the taker fee schedule of a pair.
+func (s *AssetPairService) Fees(pair string) []FeeTier {
+	info := s.GetInfo(pair)
+	if info == nil {
+		return nil
+	}
+	return info.Fees
+}
+
+// FeesMaker returns the maker fee schedule of a pair, falling back to the
+// taker schedule for pairs without separate maker fees.
+func (s *AssetPairService) FeesMaker(pair string) []FeeTier {
+	info := s.GetInfo(pair)
+	if info == nil {
+		return nil
+	}
+	if len(info.FeesMaker) == 0 {
+		return info.Fees
+	}
+	return info.FeesMaker
+}
+
 type AssetPairResponse struct {
 	Error  []interface{}             `json:"error"`
 	Result map[string]*AssetPairInfo `json:"result"`
 }
 
+func (r *AssetPairResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
 type AssetPairInfo struct {
-	Pair    string // Not in JSON response.
-	AltName string `json:"altname"`
-	WsName  string `json:"wsname"`
+	Pair              string    // Not in JSON response.
+	AltName           string    `json:"altname"`
+	WsName            string    `json:"wsname"`
+	AClassBase        string    `json:"aclass_base"`
+	Base              string    `json:"base"`
+	AClassQuote       string    `json:"aclass_quote"`
+	Quote             string    `json:"quote"`
+	Lot               string    `json:"lot"`
+	PairDecimals      int       `json:"pair_decimals"`
+	CostDecimals      int       `json:"cost_decimals"`
+	LotDecimals       int       `json:"lot_decimals"`
+	LotMultiplier     int       `json:"lot_multiplier"`
+	LeverageBuy       []int     `json:"leverage_buy"`
+	LeverageSell      []int     `json:"leverage_sell"`
+	Fees              []FeeTier `json:"fees"`
+	FeesMaker         []FeeTier `json:"fees_maker"`
+	FeeVolumeCurrency string    `json:"fee_volume_currency"`
+	MarginCall        int       `json:"margin_call"`
+	MarginStop        int       `json:"margin_stop"`
+	OrderMin          Decimal   `json:"ordermin"`
+	CostMin           Decimal   `json:"costmin"`
+	TickSize          Decimal   `json:"tick_size"`
+	Status            string    `json:"status"`
+}
+
+// FeeTier is the fee, as a percentage, that applies from a 30 day volume.
+type FeeTier struct {
+	Volume Decimal
+	Fee    Decimal
+}
+
+// UnmarshalJSON decodes a fee tier from its [volume, fee] array form.
+func (t *FeeTier) UnmarshalJSON(data []byte) error {
+	var values []Decimal
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	if len(values) != 2 {
+		return fmt.Errorf("invalid fee tier: %s", string(data))
+	}
+	t.Volume = values[0]
+	t.Fee = values[1]
+	return nil
 }
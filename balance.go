@@ -0,0 +1,110 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+type BalanceResponse struct {
+	Error []interface{}
+
+	// Result is the balance of each asset, keyed by the asset name used
+	// by the REST API.
+	Result map[string]Decimal
+}
+
+func (r *BalanceResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// Balance gets the balance of all assets held.
+func (c *RestClient) Balance() (*BalanceResponse, error) {
+	var raw struct {
+		Error  []interface{}     `json:"error"`
+		Result map[string]string `json:"result"`
+	}
+	if err := c.postAndDecode("/0/private/Balance", nil, &raw); err != nil {
+		return nil, err
+	}
+	response := &BalanceResponse{
+		Error:  raw.Error,
+		Result: map[string]Decimal{},
+	}
+	for asset, value := range raw.Result {
+		balance, err := ParseDecimal(value)
+		if err != nil {
+			return nil, decodeError(err)
+		}
+		response.Result[asset] = balance
+	}
+	return response, nil
+}
+
+type TradeBalanceResponse struct {
+	Error  []interface{} `json:"error"`
+	Result struct {
+		// Combined balance of all currencies.
+		EquivalentBalance Decimal `json:"eb"`
+
+		// Combined balance of all equity currencies.
+		TradeBalance Decimal `json:"tb"`
+
+		// Margin amount of open positions.
+		Margin Decimal `json:"m"`
+
+		// Unrealized net profit/loss of open positions.
+		UnrealizedNet Decimal `json:"n"`
+
+		// Cost basis of open positions.
+		CostBasis Decimal `json:"c"`
+
+		// Current floating valuation of open positions.
+		Valuation Decimal `json:"v"`
+
+		// Trade balance plus unrealized net profit/loss.
+		Equity Decimal `json:"e"`
+
+		// Equity minus initial margin.
+		FreeMargin Decimal `json:"mf"`
+
+		// Equity as a percentage of initial margin. Only set if there
+		// are open positions.
+		MarginLevel Decimal `json:"ml"`
+	} `json:"result"`
+}
+
+func (r *TradeBalanceResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// TradeBalance gets the trade balance, expressed in asset. An empty asset
+// uses the default of ZUSD.
+func (c *RestClient) TradeBalance(asset string) (*TradeBalanceResponse, error) {
+	params := map[string]interface{}{}
+	if asset != "" {
+		params["asset"] = asset
+	}
+	var response TradeBalanceResponse
+	if err := c.postAndDecode("/0/private/TradeBalance", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
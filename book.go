@@ -0,0 +1,410 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BookLevel is a single price level in an order book.
+type BookLevel struct {
+	// Price and Volume keep the decimal places sent by the server, which
+	// are required to calculate the book checksum.
+	Price     Decimal
+	Volume    Decimal
+	Timestamp float64
+
+	// Republish is set on updates that re-send a level due to a change in
+	// the order of levels, not a change in volume.
+	Republish bool
+}
+
+// BookSnapshot is the initial state of the book sent after subscribing.
+type BookSnapshot struct {
+	Pair  string
+	Depth int
+	Asks  []BookLevel
+	Bids  []BookLevel
+}
+
+// BookUpdate is an incremental change to a book. A level with a volume of
+// zero is to be removed from the book.
+type BookUpdate struct {
+	Pair  string
+	Depth int
+	Asks  []BookLevel
+	Bids  []BookLevel
+
+	// Checksum is the CRC32 of the top 10 levels of the book after the
+	// update is applied. Only valid if HasChecksum is true.
+	Checksum    uint32
+	HasChecksum bool
+}
+
+// BookResync is returned by WebSocket.Decode when a book failed checksum
+// verification and a new snapshot has been requested.
+type BookResync struct {
+	Pair  string
+	Depth int
+}
+
+// BookChecksumError is returned when the local book no longer matches the
+// checksum sent by the server.
+type BookChecksumError struct {
+	Pair     string
+	Expected uint32
+	Actual   uint32
+}
+
+func (e *BookChecksumError) Error() string {
+	return fmt.Sprintf("book checksum mismatch for %s: expected %d, got %d",
+		e.Pair, e.Expected, e.Actual)
+}
+
+// ChecksumPolicy determines what happens when a book update fails checksum
+// verification.
+type ChecksumPolicy int
+
+const (
+	// ChecksumPolicyError returns a BookChecksumError and leaves the book
+	// as is.
+	ChecksumPolicyError ChecksumPolicy = iota
+
+	// ChecksumPolicyResync drops the book and, when attached to a
+	// WebSocket, unsubscribes and resubscribes to get a fresh snapshot.
+	ChecksumPolicyResync
+)
+
+// DecodeBook decodes the payload objects of a book message into either a
+// BookSnapshot or a BookUpdate. An update with both asks and bids arrives
+// as two separate objects.
+func DecodeBook(payload []interface{}) (interface{}, error) {
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("no payload in book event")
+	}
+
+	first, ok := payload[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid type for book event")
+	}
+
+	if _, ok := first["as"]; ok {
+		snapshot := &BookSnapshot{}
+		var err error
+		if snapshot.Asks, err = decodeBookLevels(first["as"]); err != nil {
+			return nil, err
+		}
+		if snapshot.Bids, err = decodeBookLevels(first["bs"]); err != nil {
+			return nil, err
+		}
+		return snapshot, nil
+	}
+
+	update := &BookUpdate{}
+	for _, item := range payload {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid type for book event")
+		}
+		if asks, ok := data["a"]; ok {
+			levels, err := decodeBookLevels(asks)
+			if err != nil {
+				return nil, err
+			}
+			update.Asks = append(update.Asks, levels...)
+		}
+		if bids, ok := data["b"]; ok {
+			levels, err := decodeBookLevels(bids)
+			if err != nil {
+				return nil, err
+			}
+			update.Bids = append(update.Bids, levels...)
+		}
+		if checksum, ok := data["c"].(string); ok {
+			value, err := strconv.ParseUint(checksum, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid book checksum: %v", err)
+			}
+			update.Checksum = uint32(value)
+			update.HasChecksum = true
+		}
+	}
+	return update, nil
+}
+
+func decodeBookLevels(input interface{}) ([]BookLevel, error) {
+	if input == nil {
+		return nil, nil
+	}
+	items, ok := input.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid book levels")
+	}
+	levels := make([]BookLevel, 0, len(items))
+	for _, item := range items {
+		values, ok := item.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid book level")
+		}
+		if len(values) < 3 {
+			return nil, fmt.Errorf("not enough values in book level")
+		}
+		var level BookLevel
+		var err error
+		if level.Price, err = parseDecimal(values[0]); err != nil {
+			return nil, err
+		}
+		if level.Volume, err = parseDecimal(values[1]); err != nil {
+			return nil, err
+		}
+		if level.Timestamp, err = parseFloat(values[2]); err != nil {
+			return nil, err
+		}
+		if len(values) > 3 && values[3] == "r" {
+			level.Republish = true
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// Book is the local state of the order book for a pair. Asks are sorted
+// lowest price first, bids highest price first.
+type Book struct {
+	Pair  string
+	Depth int
+	Asks  []BookLevel
+	Bids  []BookLevel
+}
+
+func (b *Book) copy() *Book {
+	return &Book{
+		Pair:  b.Pair,
+		Depth: b.Depth,
+		Asks:  append([]BookLevel{}, b.Asks...),
+		Bids:  append([]BookLevel{}, b.Bids...),
+	}
+}
+
+// BookManager maintains a local copy of the order book for each pair from
+// snapshots and updates received on the book channel.
+type BookManager struct {
+	lock   sync.RWMutex
+	depth  int
+	policy ChecksumPolicy
+	books  map[string]*Book
+
+	// Pairs that failed checksum verification and are waiting on a new
+	// snapshot.
+	resyncing map[string]bool
+}
+
+// NewBookManager creates a BookManager. Books are always maintained to the
+// depth of the subscription, as required to verify checksums. If depth is
+// greater than zero, the books returned by Book are limited to that many
+// levels per side.
+func NewBookManager(depth int) *BookManager {
+	return &BookManager{
+		depth:     depth,
+		books:     map[string]*Book{},
+		resyncing: map[string]bool{},
+	}
+}
+
+// SetChecksumPolicy sets the action taken on a checksum mismatch. The
+// default is ChecksumPolicyError.
+func (m *BookManager) SetChecksumPolicy(policy ChecksumPolicy) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.policy = policy
+}
+
+// ChecksumPolicy returns the current checksum policy.
+func (m *BookManager) ChecksumPolicy() ChecksumPolicy {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.policy
+}
+
+// Apply applies a decoded BookSnapshot or BookUpdate. Other types are
+// ignored.
+func (m *BookManager) Apply(event interface{}) error {
+	switch v := event.(type) {
+	case *BookSnapshot:
+		m.ApplySnapshot(v)
+	case *BookUpdate:
+		return m.ApplyUpdate(v)
+	}
+	return nil
+}
+
+// ApplySnapshot replaces the book for the pair of the snapshot.
+func (m *BookManager) ApplySnapshot(snapshot *BookSnapshot) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	book := &Book{
+		Pair:  snapshot.Pair,
+		Depth: snapshot.Depth,
+	}
+	for _, level := range snapshot.Asks {
+		book.Asks = applyBookLevel(book.Asks, level, false)
+	}
+	for _, level := range snapshot.Bids {
+		book.Bids = applyBookLevel(book.Bids, level, true)
+	}
+	if book.Depth <= 0 {
+		book.Depth = defaultBookDepth
+	}
+	book.truncate()
+	m.books[snapshot.Pair] = book
+	delete(m.resyncing, snapshot.Pair)
+}
+
+// ApplyUpdate applies an update to the book for its pair. An error is
+// returned if no snapshot has been applied for the pair, or a
+// *BookChecksumError if the book fails checksum verification after the
+// update. Updates for a pair waiting on a resync are dropped.
+func (m *BookManager) ApplyUpdate(update *BookUpdate) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	book, ok := m.books[update.Pair]
+	if !ok {
+		if m.resyncing[update.Pair] {
+			return nil
+		}
+		return fmt.Errorf("no book snapshot for pair: %s", update.Pair)
+	}
+	for _, level := range update.Asks {
+		book.Asks = applyBookLevel(book.Asks, level, false)
+	}
+	for _, level := range update.Bids {
+		book.Bids = applyBookLevel(book.Bids, level, true)
+	}
+	book.truncate()
+	if update.HasChecksum {
+		checksum := book.Checksum()
+		if checksum != update.Checksum {
+			if m.policy == ChecksumPolicyResync {
+				delete(m.books, update.Pair)
+				m.resyncing[update.Pair] = true
+			}
+			return &BookChecksumError{
+				Pair:     update.Pair,
+				Expected: update.Checksum,
+				Actual:   checksum,
+			}
+		}
+	}
+	return nil
+}
+
+// Checksum calculates the CRC32 checksum of the top 10 levels of the book
+// as done by the server.
+func (b *Book) Checksum() uint32 {
+	var buf strings.Builder
+	for i := 0; i < len(b.Asks) && i < 10; i++ {
+		writeChecksumLevel(&buf, b.Asks[i])
+	}
+	for i := 0; i < len(b.Bids) && i < 10; i++ {
+		writeChecksumLevel(&buf, b.Bids[i])
+	}
+	return crc32.ChecksumIEEE([]byte(buf.String()))
+}
+
+func writeChecksumLevel(buf *strings.Builder, level BookLevel) {
+	for _, value := range []string{level.Price.String(), level.Volume.String()} {
+		value = strings.Replace(value, ".", "", 1)
+		value = strings.TrimLeft(value, "0")
+		buf.WriteString(value)
+	}
+}
+
+// Book returns a copy of the current book for a pair, or nil if there is
+// no book for the pair. The checksum of a book limited to fewer than 10
+// levels will not match the server.
+func (m *BookManager) Book(pair string) *Book {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	book, ok := m.books[pair]
+	if !ok {
+		return nil
+	}
+	view := book.copy()
+	if m.depth > 0 && m.depth < view.Depth {
+		view.Depth = m.depth
+		view.truncate()
+	}
+	return view
+}
+
+// Remove drops the book for a pair.
+func (m *BookManager) Remove(pair string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.books, pair)
+	delete(m.resyncing, pair)
+}
+
+func (b *Book) truncate() {
+	if b.Depth <= 0 {
+		return
+	}
+	if len(b.Asks) > b.Depth {
+		b.Asks = b.Asks[:b.Depth]
+	}
+	if len(b.Bids) > b.Depth {
+		b.Bids = b.Bids[:b.Depth]
+	}
+}
+
+// applyBookLevel inserts, replaces or removes a level keeping the side
+// sorted; descending for bids, ascending for asks.
+func applyBookLevel(levels []BookLevel, level BookLevel, descending bool) []BookLevel {
+	i := sort.Search(len(levels), func(i int) bool {
+		if descending {
+			return levels[i].Price.Cmp(level.Price) <= 0
+		}
+		return levels[i].Price.Cmp(level.Price) >= 0
+	})
+	found := i < len(levels) && levels[i].Price.Equal(level.Price)
+	if level.Volume.IsZero() {
+		if found {
+			levels = append(levels[:i], levels[i+1:]...)
+		}
+		return levels
+	}
+	if found {
+		levels[i] = level
+		return levels
+	}
+	levels = append(levels, BookLevel{})
+	copy(levels[i+1:], levels[i:])
+	levels[i] = level
+	return levels
+}
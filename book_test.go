@@ -0,0 +1,200 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// The book snapshot from the Kraken checksum documentation, with checksum
+// 974947235.
+const testBookSnapshot = `[10,{"as":[` +
+	`["0.05005","0.00000500","1582905487.684110"],` +
+	`["0.05010","0.00000500","1582905486.187983"],` +
+	`["0.05015","0.00000500","1582905484.480241"],` +
+	`["0.05020","0.00000500","1582905486.645658"],` +
+	`["0.05025","0.00000500","1582905486.859009"],` +
+	`["0.05030","0.00000500","1582905488.601486"],` +
+	`["0.05035","0.00000500","1582905488.357312"],` +
+	`["0.05040","0.00000500","1582905488.785484"],` +
+	`["0.05045","0.00000500","1582905485.302661"],` +
+	`["0.05050","0.00000500","1582905486.157467"]],"bs":[` +
+	`["0.05000","0.00000500","1582905487.439814"],` +
+	`["0.04995","0.00000500","1582905485.119396"],` +
+	`["0.04990","0.00000500","1582905486.432052"],` +
+	`["0.04980","0.00000500","1582905480.609351"],` +
+	`["0.04975","0.00000500","1582905476.793880"],` +
+	`["0.04970","0.00000500","1582905486.767461"],` +
+	`["0.04965","0.00000500","1582905481.767528"],` +
+	`["0.04960","0.00000500","1582905487.378907"],` +
+	`["0.04955","0.00000500","1582905483.626664"],` +
+	`["0.04950","0.00000500","1582905488.509872"]]},"book-10","XBT/USD"]`
+
+const testBookSubscribed = `{"channelID":10,"channelName":"book-10",` +
+	`"event":"subscriptionStatus","pair":"XBT/USD","status":"subscribed",` +
+	`"subscription":{"depth":10,"name":"book"}}`
+
+func decodeTestSnapshot(t *testing.T) *BookSnapshot {
+	decoded, err := decodeArray([]byte(testBookSnapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	book, err := DecodeBook(decoded[1:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, ok := book.(*BookSnapshot)
+	if !ok {
+		t.Fatalf("expected *BookSnapshot, got %T", book)
+	}
+	snapshot.Pair = "XBT/USD"
+	snapshot.Depth = 10
+	return snapshot
+}
+
+func TestBookChecksum(t *testing.T) {
+	books := NewBookManager(0)
+	books.ApplySnapshot(decodeTestSnapshot(t))
+	book := books.Book("XBT/USD")
+	if checksum := book.Checksum(); checksum != 974947235 {
+		t.Fatalf("expected checksum 974947235, got %d", checksum)
+	}
+}
+
+func TestBookManagerDepth(t *testing.T) {
+	books := NewBookManager(5)
+	books.ApplySnapshot(decodeTestSnapshot(t))
+
+	book := books.Book("XBT/USD")
+	if len(book.Asks) != 5 || len(book.Bids) != 5 {
+		t.Fatalf("expected 5 levels, got %d asks and %d bids",
+			len(book.Asks), len(book.Bids))
+	}
+
+	// The full subscribed book is still maintained for the checksum.
+	err := books.ApplyUpdate(&BookUpdate{
+		Pair:        "XBT/USD",
+		Depth:       10,
+		Asks:        []BookLevel{{Price: MustParseDecimal("0.05005"), Volume: MustParseDecimal("0.00000600")}},
+		Checksum:    2078276397,
+		HasChecksum: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecodeBookResync(t *testing.T) {
+	received := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var message map[string]interface{}
+			if err := conn.ReadJSON(&message); err != nil {
+				return
+			}
+			received <- message
+		}
+	}))
+	defer server.Close()
+
+	ws, err := openWebSocket("ws" + strings.TrimPrefix(server.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	books := NewBookManager(0)
+	books.SetChecksumPolicy(ChecksumPolicyResync)
+	ws.SetBookManager(books)
+
+	decode := func(input string) interface{} {
+		t.Helper()
+		v, err := ws.Decode([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	decode(testBookSubscribed)
+	if _, ok := decode(testBookSnapshot).(*BookSnapshot); !ok {
+		t.Fatal("expected a snapshot")
+	}
+
+	// A volume change, then a removal pushing a new level into the
+	// depth, both with valid checksums.
+	if _, ok := decode(`[10,{"a":[["0.05005","0.00000600","1582905487.7"]],"c":"2078276397"},"book-10","XBT/USD"]`).(*BookUpdate); !ok {
+		t.Fatal("expected an update")
+	}
+	if _, ok := decode(`[10,{"a":[["0.05005","0.00000000","1582905487.8"],["0.05055","0.00000500","1582905487.8","r"]],"c":"3559752196"},"book-10","XBT/USD"]`).(*BookUpdate); !ok {
+		t.Fatal("expected an update")
+	}
+	if len(books.Book("XBT/USD").Asks) != 10 {
+		t.Fatal("expected the book to stay at the subscribed depth")
+	}
+
+	// A mismatch drops the book and resubscribes.
+	resync, ok := decode(`[10,{"b":[["0.05000","0.00000100","1582905487.9"]],"c":"1"},"book-10","XBT/USD"]`).(*BookResync)
+	if !ok {
+		t.Fatal("expected a resync")
+	}
+	if resync.Pair != "XBT/USD" || resync.Depth != 10 {
+		t.Fatalf("unexpected resync: %+v", resync)
+	}
+	if books.Book("XBT/USD") != nil {
+		t.Fatal("expected the book to be dropped")
+	}
+	for _, event := range []string{"unsubscribe", "subscribe"} {
+		select {
+		case message := <-received:
+			if message["event"] != event {
+				t.Fatalf("expected %s, got %v", event, message["event"])
+			}
+			subscription, _ := message["subscription"].(map[string]interface{})
+			if subscription["name"] != "book" || subscription["depth"] != float64(10) {
+				t.Fatalf("unexpected subscription: %v", subscription)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", event)
+		}
+	}
+
+	// Updates are ignored until the new snapshot arrives.
+	decode(`[10,{"b":[["0.05000","0.00000200","1582905488.0"]],"c":"1"},"book-10","XBT/USD"]`)
+	decode(testBookSnapshot)
+	if checksum := books.Book("XBT/USD").Checksum(); checksum != 974947235 {
+		t.Fatalf("expected checksum 974947235, got %d", checksum)
+	}
+}
@@ -0,0 +1,358 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number for prices, volumes and amounts. It
+// is stored as an arbitrary precision integer and the number of digits
+// after the decimal point, so values decoded from the API keep the
+// precision they were sent with.
+//
+// The zero value is 0. Decimals are immutable; arithmetic returns a new
+// value.
+type Decimal struct {
+	value *big.Int
+	scale int32
+}
+
+// roundDirection is the direction to round in, with roundNearest rounding
+// half away from zero.
+type roundDirection int
+
+const (
+	roundNearest roundDirection = iota
+	roundDown
+	roundUp
+)
+
+var bigOne = big.NewInt(1)
+var bigTen = big.NewInt(10)
+
+// NewDecimal returns the decimal value * 10^-scale.
+func NewDecimal(value int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{value: new(big.Int).Mul(big.NewInt(value), pow10(-scale))}
+	}
+	return Decimal{value: big.NewInt(value), scale: scale}
+}
+
+// NewDecimalFromFloat returns the shortest decimal that converts back to
+// the float. It panics if the float is NaN or infinite.
+func NewDecimalFromFloat(value float64) Decimal {
+	d, err := ParseDecimal(strconv.FormatFloat(value, 'f', -1, 64))
+	if err != nil {
+		panic(fmt.Sprintf("krakenapi: invalid decimal float: %v", value))
+	}
+	return d
+}
+
+// maxDecimalExponent limits the exponent and number of decimal places
+// ParseDecimal accepts, so input cannot expand into a huge number.
+const maxDecimalExponent = 64
+
+// ParseDecimal parses a decimal number, with an optional sign and
+// exponent.
+func ParseDecimal(input string) (Decimal, error) {
+	s := input
+	exponent := int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil || e > maxDecimalExponent || e < -maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("invalid decimal: %q", input)
+		}
+		exponent = e
+		s = s[:i]
+	}
+
+	digits := s
+	scale := int64(0)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits = s[:i] + s[i+1:]
+		scale = int64(len(s) - i - 1)
+	}
+	unsigned := strings.TrimLeft(digits, "+-")
+	if unsigned == "" || len(digits)-len(unsigned) > 1 ||
+		strings.Trim(unsigned, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", input)
+	}
+
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", input)
+	}
+	scale -= exponent
+	if scale > maxDecimalExponent || scale < -maxDecimalExponent {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", input)
+	}
+	if scale < 0 {
+		value.Mul(value, pow10(int32(-scale)))
+		scale = 0
+	}
+	return Decimal{value: value, scale: int32(scale)}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on invalid input.
+func MustParseDecimal(input string) Decimal {
+	d, err := ParseDecimal(input)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) int() *big.Int {
+	if d.value == nil {
+		return new(big.Int)
+	}
+	return d.value
+}
+
+// rescale returns the unscaled value of d at a scale not less than its own.
+// Reducing the scale would drop digits, which needs round.
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale < d.scale {
+		panic(fmt.Sprintf("krakenapi: rescale from %d to %d decimals", d.scale, scale))
+	}
+	if scale == d.scale {
+		return d.int()
+	}
+	return new(big.Int).Mul(d.int(), pow10(scale-d.scale))
+}
+
+// align returns the unscaled values of d and other at a common scale.
+func (d Decimal) align(other Decimal) (*big.Int, *big.Int, int32) {
+	scale := d.scale
+	if other.scale > scale {
+		scale = other.scale
+	}
+	return d.rescale(scale), other.rescale(scale), scale
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b, scale := d.align(other)
+	return Decimal{value: new(big.Int).Add(a, b), scale: scale}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b, scale := d.align(other)
+	return Decimal{value: new(big.Int).Sub(a, b), scale: scale}
+}
+
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{
+		value: new(big.Int).Mul(d.int(), other.int()),
+		scale: d.scale + other.scale,
+	}
+}
+
+// Div returns d / other rounded half away from zero to the given number of
+// decimal places. It panics if other is zero.
+func (d Decimal) Div(other Decimal, places int32) Decimal {
+	if other.Sign() == 0 {
+		panic("krakenapi: decimal division by zero")
+	}
+	if places < 0 {
+		places = 0
+	}
+	// Divide with one extra digit, truncated, so the quotient can be
+	// rounded.
+	scale := places + 1
+	numerator := new(big.Int).Mul(d.int(), pow10(other.scale+scale))
+	denominator := new(big.Int).Mul(other.int(), pow10(d.scale))
+	quotient := new(big.Int).Quo(numerator, denominator)
+	return Decimal{value: quotient, scale: scale}.round(places, roundNearest)
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp returns -1, 0 or +1 if d is less than, equal to or greater than
+// other.
+func (d Decimal) Cmp(other Decimal) int {
+	a, b, _ := d.align(other)
+	return a.Cmp(b)
+}
+
+// Equal returns true if d and other have the same value, regardless of
+// their number of decimal places.
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+func (d Decimal) LessThan(other Decimal) bool {
+	return d.Cmp(other) < 0
+}
+
+func (d Decimal) GreaterThan(other Decimal) bool {
+	return d.Cmp(other) > 0
+}
+
+// Places returns the number of digits after the decimal point.
+func (d Decimal) Places() int32 {
+	return d.scale
+}
+
+// Round rounds half away from zero to the given number of decimal places.
+func (d Decimal) Round(places int32) Decimal {
+	return d.round(places, roundNearest)
+}
+
+// Floor rounds toward negative infinity to the given number of decimal
+// places.
+func (d Decimal) Floor(places int32) Decimal {
+	return d.round(places, roundDown)
+}
+
+// Ceil rounds toward positive infinity to the given number of decimal
+// places.
+func (d Decimal) Ceil(places int32) Decimal {
+	return d.round(places, roundUp)
+}
+
+// Truncate rounds toward zero to the given number of decimal places.
+func (d Decimal) Truncate(places int32) Decimal {
+	if d.Sign() < 0 {
+		return d.Ceil(places)
+	}
+	return d.Floor(places)
+}
+
+func (d Decimal) round(places int32, dir roundDirection) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if places >= d.scale {
+		return d
+	}
+	divisor := pow10(d.scale - places)
+	quotient, remainder := new(big.Int).QuoRem(d.int(), divisor, new(big.Int))
+	if remainder.Sign() != 0 {
+		switch dir {
+		case roundDown:
+			if remainder.Sign() < 0 {
+				quotient.Sub(quotient, bigOne)
+			}
+		case roundUp:
+			if remainder.Sign() > 0 {
+				quotient.Add(quotient, bigOne)
+			}
+		default:
+			twice := new(big.Int).Abs(remainder)
+			twice.Lsh(twice, 1)
+			if twice.Cmp(divisor) >= 0 {
+				if remainder.Sign() < 0 {
+					quotient.Sub(quotient, bigOne)
+				} else {
+					quotient.Add(quotient, bigOne)
+				}
+			}
+		}
+	}
+	return Decimal{value: quotient, scale: places}
+}
+
+// Float64 returns the nearest float64 to d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns d with its decimal places, for example "0.10000" for a
+// value decoded from "0.10000".
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		point := len(digits) - int(d.scale)
+		digits = digits[:point] + "." + digits[point:]
+	}
+	if d.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// StringFixed returns d rounded half away from zero to, and padded with
+// zeros to, the given number of decimal places.
+func (d Decimal) StringFixed(places int32) string {
+	rounded := d.Round(places)
+	if rounded.scale < places {
+		rounded = Decimal{value: rounded.rescale(places), scale: places}
+	}
+	return rounded.String()
+}
+
+// MarshalJSON encodes d as a string to preserve its precision.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON decodes a decimal from either a JSON string or number. A
+// null leaves d unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	input := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(input)
+		if err != nil {
+			return fmt.Errorf("invalid decimal: %s", input)
+		}
+		input = unquoted
+	}
+	value, err := ParseDecimal(input)
+	if err != nil {
+		return err
+	}
+	*d = value
+	return nil
+}
@@ -0,0 +1,188 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		places   int32
+	}{
+		{"0", "0", 0},
+		{"1.50", "1.50", 2},
+		{"+2", "2", 0},
+		{"-0.001", "-0.001", 3},
+		{"0.00000500", "0.00000500", 8},
+		{"1e3", "1000", 0},
+		{"1.5e-2", "0.015", 3},
+		{"-2.5E1", "-25", 0},
+		{"1e64", "1" + strings.Repeat("0", 64), 0},
+		{"1e-64", "0." + strings.Repeat("0", 63) + "1", 64},
+		{"10e-64", "0." + strings.Repeat("0", 62) + "10", 64},
+		{"123456789012345678901234567890.123", "123456789012345678901234567890.123", 3},
+	}
+	for _, test := range tests {
+		d, err := ParseDecimal(test.input)
+		if err != nil {
+			t.Errorf("%q: %v", test.input, err)
+			continue
+		}
+		if d.String() != test.expected || d.Places() != test.places {
+			t.Errorf("%q: expected %s with %d places, got %s with %d places",
+				test.input, test.expected, test.places, d, d.Places())
+		}
+	}
+
+	for _, input := range []string{"", "-", "abc", "1.2.3", "--1", "1-2",
+		"1e", "1e1.5", "1e2147483648", "0.1e-2147483647", "1e999999999",
+		"1e-999999999", "1e65", "1e-65", "0.1e-64", "1.5e-64"} {
+		if d, err := ParseDecimal(input); err == nil {
+			t.Errorf("%q: expected an error, got %s", input, d)
+		}
+	}
+}
+
+func TestDecimalRounding(t *testing.T) {
+	tests := []struct {
+		input    string
+		places   int32
+		round    string
+		floor    string
+		ceil     string
+		truncate string
+	}{
+		{"1.25", 1, "1.3", "1.2", "1.3", "1.2"},
+		{"1.24", 1, "1.2", "1.2", "1.3", "1.2"},
+		{"-1.25", 1, "-1.3", "-1.3", "-1.2", "-1.2"},
+		{"-1.24", 1, "-1.2", "-1.3", "-1.2", "-1.2"},
+		{"1.20", 1, "1.2", "1.2", "1.2", "1.2"},
+		{"0.004", 2, "0.00", "0.00", "0.01", "0.00"},
+		{"-0.004", 2, "0.00", "-0.01", "0.00", "0.00"},
+		{"15.5", 0, "16", "15", "16", "15"},
+		{"15.5", -1, "16", "15", "16", "15"},
+		{"1.25", 4, "1.25", "1.25", "1.25", "1.25"},
+	}
+	for _, test := range tests {
+		d := MustParseDecimal(test.input)
+		results := []struct {
+			name     string
+			got      Decimal
+			expected string
+		}{
+			{"Round", d.Round(test.places), test.round},
+			{"Floor", d.Floor(test.places), test.floor},
+			{"Ceil", d.Ceil(test.places), test.ceil},
+			{"Truncate", d.Truncate(test.places), test.truncate},
+		}
+		for _, result := range results {
+			if result.got.String() != result.expected {
+				t.Errorf("%s(%s, %d): expected %s, got %s", result.name,
+					test.input, test.places, result.expected, result.got)
+			}
+		}
+	}
+}
+
+func TestDecimalDiv(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		places   int32
+		expected string
+	}{
+		{"1", "3", 4, "0.3333"},
+		{"2", "3", 4, "0.6667"},
+		{"-2", "3", 4, "-0.6667"},
+		{"2", "-3", 4, "-0.6667"},
+		{"1", "8", 2, "0.13"},
+		{"-1", "8", 2, "-0.13"},
+		{"0.15", "0.05", 0, "3"},
+		{"100", "0.001", 2, "100000.00"},
+	}
+	for _, test := range tests {
+		got := MustParseDecimal(test.a).Div(MustParseDecimal(test.b), test.places)
+		if got.String() != test.expected {
+			t.Errorf("%s / %s to %d places: expected %s, got %s",
+				test.a, test.b, test.places, test.expected, got)
+		}
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"0.00000500"`, `"0.00000500"`},
+		{`"-12.5"`, `"-12.5"`},
+		{`12.50`, `"12.50"`},
+		{`1e-3`, `"0.001"`},
+	}
+	for _, test := range tests {
+		var d Decimal
+		if err := json.Unmarshal([]byte(test.input), &d); err != nil {
+			t.Errorf("%s: %v", test.input, err)
+			continue
+		}
+		encoded, err := json.Marshal(d)
+		if err != nil {
+			t.Errorf("%s: %v", test.input, err)
+			continue
+		}
+		if string(encoded) != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.input, test.expected, encoded)
+		}
+	}
+
+	d := MustParseDecimal("1.5")
+	if err := json.Unmarshal([]byte("null"), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.String() != "1.5" {
+		t.Errorf("null: expected 1.5 to be unchanged, got %s", d)
+	}
+
+	for _, input := range []string{`"abc"`, `""`, `true`} {
+		if err := json.Unmarshal([]byte(input), &d); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
+
+func TestDecimalRescale(t *testing.T) {
+	if got := MustParseDecimal("1.5").StringFixed(4); got != "1.5000" {
+		t.Errorf("expected 1.5000, got %s", got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected rescale to a smaller scale to panic")
+		}
+	}()
+	MustParseDecimal("1.25").rescale(1)
+}
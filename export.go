@@ -0,0 +1,291 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Values of AddExportRequest.Report.
+const (
+	ExportReportTrades  = "trades"
+	ExportReportLedgers = "ledgers"
+)
+
+// Values of AddExportRequest.Format.
+const (
+	ExportFormatCSV = "CSV"
+	ExportFormatTSV = "TSV"
+)
+
+// Values of ExportStatus.Status.
+const (
+	ExportStatusQueued     = "Queued"
+	ExportStatusProcessing = "Processing"
+	ExportStatusProcessed  = "Processed"
+)
+
+type AddExportRequest struct {
+	Report      string
+	Format      string
+	Description string
+
+	// Fields to include, all fields if empty.
+	Fields []string
+
+	// StartTime and EndTime limit the report to a range of unix times.
+	// An EndTime of 0 is now.
+	StartTime int64
+	EndTime   int64
+}
+
+type AddExportResponse struct {
+	Error  []interface{} `json:"error"`
+	Result struct {
+		ID string `json:"id"`
+	} `json:"result"`
+}
+
+func (r *AddExportResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// AddExport requests the creation of a report. The report is generated
+// asynchronously; see ExportStatus and RetrieveExport, or Export to do it
+// all in one call.
+func (c *RestClient) AddExport(request AddExportRequest) (*AddExportResponse, error) {
+	params := map[string]interface{}{
+		"report":      request.Report,
+		"description": request.Description,
+	}
+	if request.Format != "" {
+		params["format"] = request.Format
+	}
+	if len(request.Fields) > 0 {
+		params["fields"] = strings.Join(request.Fields, ",")
+	}
+	if request.StartTime > 0 {
+		params["starttm"] = request.StartTime
+	}
+	if request.EndTime > 0 {
+		params["endtm"] = request.EndTime
+	}
+	var response AddExportResponse
+	if err := c.postAndDecode("/0/private/AddExport", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+type ExportStatus struct {
+	ID            string `json:"id"`
+	Description   string `json:"descr"`
+	Format        string `json:"format"`
+	Report        string `json:"report"`
+	SubType       string `json:"subtype"`
+	Status        string `json:"status"`
+	Fields        string `json:"fields"`
+	CreatedTime   int64  `json:"createdtm,string"`
+	ExpireTime    int64  `json:"expiretm,string"`
+	StartTime     int64  `json:"starttm,string"`
+	CompletedTime int64  `json:"completedtm,string"`
+	DataStartTime int64  `json:"datastarttm,string"`
+	DataEndTime   int64  `json:"dataendtm,string"`
+	AClass        string `json:"aclass"`
+	Asset         string `json:"asset"`
+}
+
+type ExportStatusResponse struct {
+	Error  []interface{}  `json:"error"`
+	Result []ExportStatus `json:"result"`
+}
+
+func (r *ExportStatusResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// ExportStatus gets the status of the exports of a report type.
+func (c *RestClient) ExportStatus(report string) (*ExportStatusResponse, error) {
+	params := map[string]interface{}{
+		"report": report,
+	}
+	var response ExportStatusResponse
+	if err := c.postAndDecode("/0/private/ExportStatus", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// RetrieveExport writes the zip archive of a processed export to w,
+// returning the number of bytes written.
+func (c *RestClient) RetrieveExport(id string, w io.Writer) (int64, error) {
+	params := map[string]interface{}{
+		"id": id,
+	}
+	httpResponse, err := c.Post("/0/private/RetrieveExport", params)
+	if err != nil {
+		return 0, RequestError{
+			NetworkError: err,
+		}
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != 200 {
+		return 0, RequestError{
+			HttpError: fmt.Errorf("%s", httpResponse.Status),
+		}
+	}
+
+	// Errors are returned as JSON instead of an archive.
+	if strings.HasPrefix(httpResponse.Header.Get("Content-Type"), "application/json") {
+		var response struct {
+			Error []interface{} `json:"error"`
+		}
+		if err := decodeHttpResponse(httpResponse, &response); err != nil {
+			return 0, decodeError(err)
+		}
+		return 0, fmt.Errorf("failed to retrieve export %s: %v", id, response.Error)
+	}
+
+	n, err := io.Copy(w, httpResponse.Body)
+	if err != nil {
+		return n, RequestError{
+			NetworkError: err,
+		}
+	}
+	return n, nil
+}
+
+// Values of the removal type for RemoveExport.
+const (
+	ExportRemoveCancel = "cancel"
+	ExportRemoveDelete = "delete"
+)
+
+type RemoveExportResponse struct {
+	Error  []interface{} `json:"error"`
+	Result struct {
+		Delete bool `json:"delete"`
+		Cancel bool `json:"cancel"`
+	} `json:"result"`
+}
+
+func (r *RemoveExportResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// RemoveExport cancels a queued or processing export, or deletes a
+// processed one.
+func (c *RestClient) RemoveExport(id string, removeType string) (*RemoveExportResponse, error) {
+	params := map[string]interface{}{
+		"id":   id,
+		"type": removeType,
+	}
+	var response RemoveExportResponse
+	if err := c.postAndDecode("/0/private/RemoveExport", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+type ExportOptions struct {
+	// PollInterval is the time between status checks, defaulting to 10
+	// seconds.
+	PollInterval time.Duration
+
+	// Timeout is the maximum time to wait for the export to be
+	// processed, defaulting to 30 minutes.
+	Timeout time.Duration
+
+	// Remove deletes the export from the server once retrieved.
+	Remove bool
+}
+
+// Export submits an export, waits for it to be processed and writes the
+// zip archive to w. The ID of the export is returned.
+func (c *RestClient) Export(request AddExportRequest, w io.Writer, options ExportOptions) (string, error) {
+	if options.PollInterval <= 0 {
+		options.PollInterval = 10 * time.Second
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 30 * time.Minute
+	}
+
+	addResponse, err := c.AddExport(request)
+	if err != nil {
+		return "", err
+	}
+	if addResponse.HasError() {
+		return "", fmt.Errorf("failed to add export: %v", addResponse.Error)
+	}
+	id := addResponse.Result.ID
+
+	start := time.Now()
+	for {
+		statusResponse, err := c.ExportStatus(request.Report)
+		if err != nil {
+			return id, err
+		}
+		if statusResponse.HasError() {
+			return id, fmt.Errorf("failed to get export status: %v",
+				statusResponse.Error)
+		}
+		status := ""
+		for _, export := range statusResponse.Result {
+			if export.ID == id {
+				status = export.Status
+				break
+			}
+		}
+		if status == "" {
+			return id, fmt.Errorf("export %s not found", id)
+		}
+		if status == ExportStatusProcessed {
+			break
+		}
+		if time.Since(start) > options.Timeout {
+			return id, fmt.Errorf("timeout waiting for export %s", id)
+		}
+		time.Sleep(options.PollInterval)
+	}
+
+	if _, err := c.RetrieveExport(id, w); err != nil {
+		return id, err
+	}
+
+	if options.Remove {
+		removeResponse, err := c.RemoveExport(id, ExportRemoveDelete)
+		if err != nil {
+			return id, err
+		}
+		if removeResponse.HasError() {
+			return id, fmt.Errorf("failed to remove export: %v",
+				removeResponse.Error)
+		}
+	}
+
+	return id, nil
+}
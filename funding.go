@@ -0,0 +1,178 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"encoding/json"
+)
+
+// DepositMethod is a method that can be used to deposit an asset.
+type DepositMethod struct {
+	Method string `json:"method"`
+
+	// Limit is the maximum net amount that can be deposited right now.
+	// Only valid if HasLimit is true.
+	Limit    Decimal `json:"-"`
+	HasLimit bool    `json:"-"`
+
+	Fee             Decimal `json:"fee"`
+	AddressSetupFee Decimal `json:"address-setup-fee"`
+	GenAddress      bool    `json:"gen-address"`
+}
+
+// UnmarshalJSON handles the limit, which is false when there is no limit.
+func (m *DepositMethod) UnmarshalJSON(data []byte) error {
+	type depositMethod DepositMethod
+	raw := struct {
+		*depositMethod
+		Limit interface{} `json:"limit"`
+	}{
+		depositMethod: (*depositMethod)(m),
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if limit, ok := raw.Limit.(string); ok {
+		value, err := ParseDecimal(limit)
+		if err != nil {
+			return err
+		}
+		m.Limit = value
+		m.HasLimit = true
+	}
+	return nil
+}
+
+type DepositMethodsResponse struct {
+	Error  []interface{}   `json:"error"`
+	Result []DepositMethod `json:"result"`
+}
+
+func (r *DepositMethodsResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// DepositMethods gets the methods available to deposit an asset.
+func (c *RestClient) DepositMethods(asset string) (*DepositMethodsResponse, error) {
+	params := map[string]interface{}{
+		"asset": asset,
+	}
+	var response DepositMethodsResponse
+	if err := c.postAndDecode("/0/private/DepositMethods", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+type DepositAddress struct {
+	Address string `json:"address"`
+
+	// ExpireTime is the unix time the address expires, 0 if it does not.
+	ExpireTime int64 `json:"expiretm,string"`
+
+	// New is true if the address has never been used.
+	New bool `json:"new"`
+
+	// Tag or memo required by some assets in addition to the address.
+	Tag  string `json:"tag"`
+	Memo string `json:"memo"`
+}
+
+type DepositAddressesResponse struct {
+	Error  []interface{}    `json:"error"`
+	Result []DepositAddress `json:"result"`
+}
+
+func (r *DepositAddressesResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// DepositAddresses gets the deposit addresses for an asset and method. If
+// generate is true a new address is created, which is only possible for
+// methods with GenAddress set.
+func (c *RestClient) DepositAddresses(asset string, method string, generate bool) (*DepositAddressesResponse, error) {
+	params := map[string]interface{}{
+		"asset":  asset,
+		"method": method,
+	}
+	if generate {
+		params["new"] = true
+	}
+	var response DepositAddressesResponse
+	if err := c.postAndDecode("/0/private/DepositAddresses", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Values of FundingStatus.Status.
+const (
+	FundingStatusInitial = "Initial"
+	FundingStatusPending = "Pending"
+	FundingStatusSettled = "Settled"
+	FundingStatusSuccess = "Success"
+	FundingStatusFailure = "Failure"
+)
+
+// FundingStatus is the status of a deposit or withdrawal.
+type FundingStatus struct {
+	Method string  `json:"method"`
+	AClass string  `json:"aclass"`
+	Asset  string  `json:"asset"`
+	RefID  string  `json:"refid"`
+	TxID   string  `json:"txid"`
+	Info   string  `json:"info"`
+	Amount Decimal `json:"amount"`
+	Fee    Decimal `json:"fee"`
+	Time   int64   `json:"time"`
+	Status string  `json:"status"`
+
+	// Additional status such as "return" or "onhold".
+	StatusProp string `json:"status-prop"`
+}
+
+type DepositStatusResponse struct {
+	Error  []interface{}   `json:"error"`
+	Result []FundingStatus `json:"result"`
+}
+
+func (r *DepositStatusResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// DepositStatus gets the status of recent deposits of an asset. If method
+// is empty deposits of all methods are returned.
+func (c *RestClient) DepositStatus(asset string, method string) (*DepositStatusResponse, error) {
+	params := map[string]interface{}{
+		"asset": asset,
+	}
+	if method != "" {
+		params["method"] = method
+	}
+	var response DepositStatusResponse
+	if err := c.postAndDecode("/0/private/DepositStatus", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,279 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Values of LedgersRequest.Type and LedgerEntry.Type.
+const (
+	LedgerTypeAll        = "all"
+	LedgerTypeDeposit    = "deposit"
+	LedgerTypeWithdrawal = "withdrawal"
+	LedgerTypeTrade      = "trade"
+	LedgerTypeMargin     = "margin"
+	LedgerTypeRollover   = "rollover"
+	LedgerTypeCredit     = "credit"
+	LedgerTypeTransfer   = "transfer"
+	LedgerTypeSettled    = "settled"
+	LedgerTypeStaking    = "staking"
+	LedgerTypeSale       = "sale"
+)
+
+// The number of entries per page returned by Ledgers.
+const ledgerPageSize = 50
+
+// LedgerEntry is a single change to the balance of an asset.
+type LedgerEntry struct {
+	LedgerID string  // Not in JSON response.
+	RefID    string  `json:"refid"`
+	Time     float64 `json:"time"`
+	Type     string  `json:"type"`
+	SubType  string  `json:"subtype"`
+	AClass   string  `json:"aclass"`
+	Asset    string  `json:"asset"`
+	Amount   Decimal `json:"amount"`
+	Fee      Decimal `json:"fee"`
+	Balance  Decimal `json:"balance"`
+}
+
+func setLedgerIDs(entries map[string]*LedgerEntry) {
+	for id, entry := range entries {
+		entry.LedgerID = id
+	}
+}
+
+type LedgersRequest struct {
+	// Assets to limit the entries to, all assets if empty.
+	Assets []string
+
+	// AClass is the asset class, defaulting to currency.
+	AClass string
+
+	// Type of entries, defaulting to all.
+	Type string
+
+	// Start and End are exclusive bounds, given as either a unix
+	// timestamp or a ledger ID.
+	Start string
+	End   string
+
+	// Ofs is the offset of the first result, for paging.
+	Ofs int
+}
+
+type LedgersResponse struct {
+	Error  []interface{} `json:"error"`
+	Result struct {
+		Ledger map[string]*LedgerEntry `json:"ledger"`
+
+		// Count is the total number of entries matching the request.
+		Count int64 `json:"count"`
+	} `json:"result"`
+}
+
+func (r *LedgersResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// Ledgers gets ledger entries, 50 at a time, most recent first. See
+// LedgerIterator to walk all entries.
+func (c *RestClient) Ledgers(request LedgersRequest) (*LedgersResponse, error) {
+	params := map[string]interface{}{}
+	if len(request.Assets) > 0 {
+		params["asset"] = strings.Join(request.Assets, ",")
+	}
+	if request.AClass != "" {
+		params["aclass"] = request.AClass
+	}
+	if request.Type != "" {
+		params["type"] = request.Type
+	}
+	if request.Start != "" {
+		params["start"] = request.Start
+	}
+	if request.End != "" {
+		params["end"] = request.End
+	}
+	if request.Ofs > 0 {
+		params["ofs"] = request.Ofs
+	}
+	var response LedgersResponse
+	if err := c.postAndDecode("/0/private/Ledgers", params, &response); err != nil {
+		return nil, err
+	}
+	setLedgerIDs(response.Result.Ledger)
+	return &response, nil
+}
+
+type QueryLedgersResponse struct {
+	Error  []interface{}           `json:"error"`
+	Result map[string]*LedgerEntry `json:"result"`
+}
+
+func (r *QueryLedgersResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// QueryLedgers gets ledger entries by ID. At most 20 IDs may be given.
+func (c *RestClient) QueryLedgers(ids ...string) (*QueryLedgersResponse, error) {
+	params := map[string]interface{}{
+		"id": strings.Join(ids, ","),
+	}
+	var response QueryLedgersResponse
+	if err := c.postAndDecode("/0/private/QueryLedgers", params, &response); err != nil {
+		return nil, err
+	}
+	setLedgerIDs(response.Result)
+	return &response, nil
+}
+
+// LedgerIterator walks all ledger entries matching a request, most recent
+// first, fetching pages as required.
+//
+//	it := NewLedgerIterator(client, LedgersRequest{})
+//	for it.Next() {
+//		fmt.Println(it.Entry().RefID)
+//	}
+//	if err := it.Err(); err != nil {
+//		log.Fatal(err)
+//	}
+type LedgerIterator struct {
+	// PageDelay is the minimum time between page requests. Requests that
+	// hit the rate limit anyways are retried after a growing delay up
+	// to MaxRetries times.
+	PageDelay  time.Duration
+	MaxRetries int
+
+	client    *RestClient
+	request   LedgersRequest
+	entries   []*LedgerEntry
+	entry     *LedgerEntry
+	seen      map[string]bool
+	offset    int
+	count     int64
+	lastFetch time.Time
+	done      bool
+	err       error
+}
+
+func NewLedgerIterator(client *RestClient, request LedgersRequest) *LedgerIterator {
+	return &LedgerIterator{
+		PageDelay:  3 * time.Second,
+		MaxRetries: 5,
+		client:     client,
+		request:    request,
+		seen:       map[string]bool{},
+		offset:     request.Ofs,
+	}
+}
+
+// Next advances to the next entry, returning false when there are no more
+// entries or an error occurred.
+func (it *LedgerIterator) Next() bool {
+	for len(it.entries) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.err = it.fetch()
+	}
+	it.entry = it.entries[0]
+	it.entries = it.entries[1:]
+	return true
+}
+
+// Entry returns the current entry.
+func (it *LedgerIterator) Entry() *LedgerEntry {
+	return it.entry
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *LedgerIterator) Err() error {
+	return it.err
+}
+
+func (it *LedgerIterator) fetch() error {
+	request := it.request
+	request.Ofs = it.offset
+
+	var response *LedgersResponse
+	delay := it.PageDelay
+	for retry := 0; ; retry++ {
+		if wait := it.PageDelay - time.Since(it.lastFetch); wait > 0 {
+			time.Sleep(wait)
+		}
+		var err error
+		response, err = it.client.Ledgers(request)
+		it.lastFetch = time.Now()
+		if err != nil {
+			return err
+		}
+		if !response.HasError() {
+			break
+		}
+		if !isRateLimitError(response.Error) || retry >= it.MaxRetries {
+			return fmt.Errorf("ledgers request failed: %v", response.Error)
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	page := make([]*LedgerEntry, 0, len(response.Result.Ledger))
+	for id, entry := range response.Result.Ledger {
+		// Entries added while paging shift the offsets, which
+		// shows up as entries seen on a previous page.
+		if it.seen[id] {
+			continue
+		}
+		it.seen[id] = true
+		page = append(page, entry)
+	}
+	sort.Slice(page, func(i, j int) bool {
+		if page[i].Time == page[j].Time {
+			return page[i].LedgerID > page[j].LedgerID
+		}
+		return page[i].Time > page[j].Time
+	})
+
+	it.count = response.Result.Count
+	it.offset += len(response.Result.Ledger)
+	if len(response.Result.Ledger) < ledgerPageSize || int64(it.offset) >= it.count {
+		it.done = true
+	}
+	it.entries = page
+	return nil
+}
+
+func isRateLimitError(errors []interface{}) bool {
+	for _, err := range errors {
+		if message, ok := err.(string); ok && strings.Contains(message, "Rate limit exceeded") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"sync"
+	"time"
+)
+
+func (s *WebSocket) touch() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.lastMessage = time.Now()
+}
+
+func (s *WebSocket) touchHeartbeat() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.lastHeartbeat = time.Now()
+}
+
+func (s *WebSocket) touchChannel(channelID int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.channelSeen == nil {
+		s.channelSeen = map[int64]time.Time{}
+	}
+	s.channelSeen[channelID] = time.Now()
+}
+
+func (s *WebSocket) forgetChannel(channelID int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.channelSeen, channelID)
+}
+
+// ping sends a ping with a new reqid, recording when it was sent so the
+// latency can be measured when the pong arrives.
+func (s *WebSocket) ping() error {
+	reqID := s.nextRequestID()
+	s.lock.Lock()
+	if s.pings == nil {
+		s.pings = map[int64]time.Time{}
+	}
+	s.pings[reqID] = time.Now()
+	s.lock.Unlock()
+	return s.writeJSON(map[string]interface{}{
+		"event": "ping",
+		"reqid": reqID,
+	})
+}
+
+// prunePings forgets pings that have not been answered within maxAge.
+func (s *WebSocket) prunePings(maxAge time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for reqID, sent := range s.pings {
+		if time.Since(sent) > maxAge {
+			delete(s.pings, reqID)
+		}
+	}
+}
+
+// touchPong returns the latency of the ping with the reqid, or 0 if
+// unknown.
+func (s *WebSocket) touchPong(reqID int64) time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sent, ok := s.pings[reqID]
+	if !ok {
+		return 0
+	}
+	s.latency = time.Since(sent)
+	delete(s.pings, reqID)
+	return s.latency
+}
+
+// nextRequestID returns a new reqid, unique for this connection.
+func (s *WebSocket) nextRequestID() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.reqID++
+	return s.reqID
+}
+
+// LastMessage returns the time the last message was decoded.
+func (s *WebSocket) LastMessage() time.Time {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.lastMessage
+}
+
+// LastHeartbeat returns the time the last heartbeat was decoded. The
+// server only sends heartbeats when there is no other traffic.
+func (s *WebSocket) LastHeartbeat() time.Time {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.lastHeartbeat
+}
+
+// ChannelLastSeen returns the time the last message for a channel was
+// decoded.
+func (s *WebSocket) ChannelLastSeen(channelID int64) (time.Time, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	seen, ok := s.channelSeen[channelID]
+	return seen, ok
+}
+
+// StaleChannels returns the IDs of the subscribed channels that have not
+// had a message within timeout.
+func (s *WebSocket) StaleChannels(timeout time.Duration) []int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	stale := []int64{}
+	for channelID, seen := range s.channelSeen {
+		if time.Since(seen) > timeout {
+			stale = append(stale, channelID)
+		}
+	}
+	return stale
+}
+
+// Latency returns the round trip time of the last ping answered with a
+// pong, or 0 if there has been none.
+func (s *WebSocket) Latency() time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.latency
+}
+
+// defaultPingInterval is the ping interval used by MonitorLiveness when
+// none is given.
+const defaultPingInterval = 10 * time.Second
+
+// LivenessMonitor pings the server at a regular interval and signals when
+// no message has been decoded within the stale timeout. Messages are only
+// seen if the connection is being read and decoded.
+type LivenessMonitor struct {
+	ws           *WebSocket
+	pingInterval time.Duration
+	staleTimeout time.Duration
+	stale        chan struct{}
+	done         chan struct{}
+	once         sync.Once
+}
+
+// MonitorLiveness starts a LivenessMonitor for the WebSocket. It stops on
+// its own once the connection has been signalled stale. A ping interval of
+// 0 uses the default of 10 seconds, and a stale timeout of 0 only pings.
+func (s *WebSocket) MonitorLiveness(pingInterval time.Duration, staleTimeout time.Duration) *LivenessMonitor {
+	if pingInterval <= 0 {
+		pingInterval = defaultPingInterval
+	}
+	monitor := &LivenessMonitor{
+		ws:           s,
+		pingInterval: pingInterval,
+		staleTimeout: staleTimeout,
+		stale:        make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	s.touch()
+	go monitor.run()
+	return monitor
+}
+
+func (m *LivenessMonitor) run() {
+	// Check for staleness at least as often as the stale timeout, so a
+	// timeout shorter than the ping interval is not detected late.
+	checkInterval := m.pingInterval
+	if m.staleTimeout > 0 && m.staleTimeout < checkInterval {
+		checkInterval = m.staleTimeout
+	}
+	// Pings without a pong are forgotten once they could no longer
+	// prevent the connection going stale.
+	maxPingAge := m.staleTimeout
+	if maxPingAge <= 0 {
+		maxPingAge = m.pingInterval
+	}
+
+	pingTicker := time.NewTicker(m.pingInterval)
+	defer pingTicker.Stop()
+	checkTicker := time.NewTicker(checkInterval)
+	defer checkTicker.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-checkTicker.C:
+			if m.staleTimeout > 0 && time.Since(m.ws.LastMessage()) > m.staleTimeout {
+				close(m.stale)
+				return
+			}
+			m.ws.prunePings(maxPingAge)
+		case <-pingTicker.C:
+			// A failed write will show up as a failed read.
+			m.ws.ping()
+		}
+	}
+}
+
+// Stale returns a channel that is closed when the connection is considered
+// stale.
+func (m *LivenessMonitor) Stale() <-chan struct{} {
+	return m.stale
+}
+
+// Stop stops the monitor.
+func (m *LivenessMonitor) Stop() {
+	m.once.Do(func() {
+		close(m.done)
+	})
+}
@@ -0,0 +1,530 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrWebSocketClosed is returned by ManagedWebSocket.Next after Close has
+// been called.
+var ErrWebSocketClosed = errors.New("websocket closed")
+
+// ErrStaleConnection is the error of the Disconnected event when a
+// connection was closed by the liveness monitor.
+var ErrStaleConnection = errors.New("stale connection")
+
+// The default depth of a book subscription made without a depth.
+const defaultBookDepth = 10
+
+// The reconnect backoff used when MinBackoff or MaxBackoff are not set.
+const defaultMinBackoff = time.Second
+const defaultMaxBackoff = time.Minute
+
+// Connected is returned by ManagedWebSocket.Next when a connection has been
+// established, before any subscriptions are replayed.
+type Connected struct {
+	// Attempts is the number of dial attempts made for this connection.
+	Attempts int
+}
+
+// Disconnected is returned by ManagedWebSocket.Next when the connection has
+// been lost. A reconnect is attempted on the next call to Next.
+type Disconnected struct {
+	Err error
+}
+
+// Resubscribed is returned by ManagedWebSocket.Next when a subscription
+// replayed after a reconnect has been confirmed with a new channel ID.
+type Resubscribed struct {
+	Name         string
+	Pair         string
+	OldChannelID int64
+	NewChannelID int64
+}
+
+// subscription identifies a subscription to a single pair. Private feeds
+// have no pair.
+type subscription struct {
+	name     string
+	pair     string
+	interval Interval
+	depth    int
+}
+
+func (s *WebSocket) subscribeTo(sub subscription) error {
+	switch sub.name {
+	case "ticker":
+		return s.SubscribeTicker(sub.pair)
+	case "ohlc":
+		return s.SubscribeOHLC(sub.interval, sub.pair)
+	case "spread":
+		return s.SubscribeSpread(sub.pair)
+	case "trade":
+		return s.SubscribeTrade(sub.pair)
+	case "book":
+		if sub.depth > 0 {
+			return s.SubscribeBookDepth(sub.depth, sub.pair)
+		}
+		return s.SubscribeBook(sub.pair)
+	case "ownTrades":
+		return s.SubscribeOwnTrades()
+	case "openOrders":
+		return s.SubscribeOpenOrders()
+	default:
+		return fmt.Errorf("unknown subscription: %s", sub.name)
+	}
+}
+
+func (s *WebSocket) unsubscribeFrom(sub subscription) error {
+	switch sub.name {
+	case "ticker":
+		return s.UnsubscribeTicker(sub.pair)
+	case "ohlc":
+		return s.UnsubscribeOHLC(sub.interval, sub.pair)
+	case "spread":
+		return s.UnsubscribeSpread(sub.pair)
+	case "trade":
+		return s.UnsubscribeTrade(sub.pair)
+	case "book":
+		return s.UnsubscribeBook(sub.depth, sub.pair)
+	default:
+		return fmt.Errorf("unsubscribe not supported: %s", sub.name)
+	}
+}
+
+// ManagedWebSocket wraps a WebSocket, reconnecting with exponential backoff
+// when the connection is lost and replaying all subscriptions on the new
+// connection.
+type ManagedWebSocket struct {
+	// MinBackoff and MaxBackoff bound the delay between reconnect attempts.
+	// The delay doubles after each failed attempt, with jitter. Zero values
+	// use the defaults of 1 second and 1 minute.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// If StaleTimeout is non-zero each connection is monitored with
+	// WebSocket.MonitorLiveness and closed if it goes stale.
+	PingInterval time.Duration
+	StaleTimeout time.Duration
+
+	lock          sync.Mutex
+	dial          func() (*WebSocket, error)
+	ws            *WebSocket
+	monitor       *LivenessMonitor
+	stale         bool
+	books         *BookManager
+	subscriptions []subscription
+	channelIDs    map[subscription]int64
+	queue         []interface{}
+	done          chan struct{}
+	closed        bool
+}
+
+// NewManagedWebSocket creates a ManagedWebSocket for the public feeds. No
+// connection is made until the first call to Next.
+func NewManagedWebSocket() *ManagedWebSocket {
+	return newManagedWebSocket(OpenWebSocket)
+}
+
+// NewManagedPrivateWebSocket creates a ManagedWebSocket for the private
+// feeds. A new token is requested with the client for each connection.
+func NewManagedPrivateWebSocket(client *RestClient) *ManagedWebSocket {
+	return newManagedWebSocket(func() (*WebSocket, error) {
+		response, err := client.GetWebSocketsToken()
+		if err != nil {
+			return nil, err
+		}
+		if response.HasError() {
+			return nil, fmt.Errorf("failed to get websocket token: %v",
+				response.Error)
+		}
+		return OpenPrivateWebSocket(response.Result.Token)
+	})
+}
+
+func newManagedWebSocket(dial func() (*WebSocket, error)) *ManagedWebSocket {
+	return &ManagedWebSocket{
+		MinBackoff:   defaultMinBackoff,
+		MaxBackoff:   defaultMaxBackoff,
+		PingInterval: defaultPingInterval,
+		dial:         dial,
+		channelIDs:   map[subscription]int64{},
+		done:         make(chan struct{}),
+	}
+}
+
+// Next returns the next decoded event, connecting first if required. In
+// addition to the events returned by WebSocket.Decode, the lifecycle events
+// Connected, Disconnected and Resubscribed are returned. An error is only
+// returned for messages that failed to decode, or ErrWebSocketClosed once
+// closed.
+func (m *ManagedWebSocket) Next() (interface{}, error) {
+	for {
+		m.lock.Lock()
+		if len(m.queue) > 0 {
+			event := m.queue[0]
+			m.queue = m.queue[1:]
+			m.lock.Unlock()
+			return event, nil
+		}
+		if m.closed {
+			m.lock.Unlock()
+			return nil, ErrWebSocketClosed
+		}
+		ws := m.ws
+		m.lock.Unlock()
+
+		if ws == nil {
+			if err := m.connect(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		payload, err := ws.Next()
+		if err != nil {
+			m.lock.Lock()
+			m.ws = nil
+			if m.monitor != nil {
+				m.monitor.Stop()
+				m.monitor = nil
+			}
+			if m.stale {
+				err = ErrStaleConnection
+			}
+			closed := m.closed
+			m.lock.Unlock()
+			ws.Close()
+			if closed {
+				return nil, ErrWebSocketClosed
+			}
+			return &Disconnected{Err: err}, nil
+		}
+
+		event, err := ws.Decode(payload)
+		if err != nil {
+			return nil, err
+		}
+		if status, ok := event.(*SubscriptionStatus); ok {
+			m.remap(status)
+		}
+		return event, nil
+	}
+}
+
+// connect dials until a connection is made and the subscriptions are
+// replayed, then queues a Connected event. A connection on which the
+// replay fails is dropped with a Disconnected event and retried.
+func (m *ManagedWebSocket) connect() error {
+	minBackoff := m.MinBackoff
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+	maxBackoff := m.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	backoff := minBackoff
+	for attempt := 1; ; attempt++ {
+		ws, err := m.dial()
+		if err == nil {
+			err = m.attach(ws, attempt)
+			if err == nil || err == ErrWebSocketClosed {
+				return err
+			}
+		}
+
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		select {
+		case <-m.done:
+			return ErrWebSocketClosed
+		case <-time.After(delay):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// attach makes ws the current connection and replays the subscriptions on
+// it.
+func (m *ManagedWebSocket) attach(ws *WebSocket, attempt int) error {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		ws.Close()
+		return ErrWebSocketClosed
+	}
+	if m.books != nil {
+		ws.SetBookManager(m.books)
+	}
+	m.ws = ws
+	m.stale = false
+	if m.StaleTimeout > 0 {
+		m.monitor = ws.MonitorLiveness(m.PingInterval, m.StaleTimeout)
+		go m.closeOnStale(ws, m.monitor)
+	}
+	subscriptions := append([]subscription{}, m.subscriptions...)
+	m.lock.Unlock()
+
+	for _, sub := range subscriptions {
+		if err := ws.subscribeTo(sub); err != nil {
+			err = fmt.Errorf("failed to resubscribe to %s %s: %v",
+				sub.name, sub.pair, err)
+			m.lock.Lock()
+			if m.ws == ws {
+				m.ws = nil
+			}
+			if m.monitor != nil {
+				m.monitor.Stop()
+				m.monitor = nil
+			}
+			m.queue = append(m.queue, &Disconnected{Err: err})
+			m.lock.Unlock()
+			ws.Close()
+			return err
+		}
+	}
+
+	m.lock.Lock()
+	m.queue = append(m.queue, &Connected{Attempts: attempt})
+	m.lock.Unlock()
+	return nil
+}
+
+// closeOnStale closes the connection when the monitor signals it stale,
+// which causes the blocked read in Next to return.
+func (m *ManagedWebSocket) closeOnStale(ws *WebSocket, monitor *LivenessMonitor) {
+	select {
+	case <-monitor.Stale():
+		m.lock.Lock()
+		if m.ws == ws {
+			m.stale = true
+		}
+		m.lock.Unlock()
+		ws.Close()
+	case <-monitor.done:
+	}
+}
+
+// remap tracks the channel ID of each subscription, queueing a Resubscribed
+// event when a subscription comes back with a new channel ID.
+func (m *ManagedWebSocket) remap(status *SubscriptionStatus) {
+	if status.Status != "subscribed" {
+		return
+	}
+	key := subscription{
+		name:     status.Subscription.Name,
+		pair:     status.Pair,
+		interval: status.Subscription.Interval,
+		depth:    status.Subscription.Depth,
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if old, ok := m.channelIDs[key]; ok && old != status.ChannelID {
+		m.queue = append(m.queue, &Resubscribed{
+			Name:         key.name,
+			Pair:         key.pair,
+			OldChannelID: old,
+			NewChannelID: status.ChannelID,
+		})
+	}
+	m.channelIDs[key] = status.ChannelID
+}
+
+// WebSocket returns the current connection, or nil if not connected. It can
+// be used for requests that are not replayed, such as AddOrder.
+func (m *ManagedWebSocket) WebSocket() *WebSocket {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.ws
+}
+
+// SetBookManager attaches a BookManager to every connection.
+func (m *ManagedWebSocket) SetBookManager(books *BookManager) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.books = books
+	if m.ws != nil {
+		m.ws.SetBookManager(books)
+	}
+}
+
+// Close closes the connection and stops reconnecting.
+func (m *ManagedWebSocket) Close() error {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return nil
+	}
+	m.closed = true
+	close(m.done)
+	ws := m.ws
+	m.lock.Unlock()
+	if ws != nil {
+		return ws.Shutdown()
+	}
+	return nil
+}
+
+// add records the subscriptions and sends them if connected. A send error
+// is returned, but the subscriptions are kept and replayed on reconnect.
+func (m *ManagedWebSocket) add(subscriptions ...subscription) error {
+	m.lock.Lock()
+	for _, sub := range subscriptions {
+		if !m.has(sub) {
+			m.subscriptions = append(m.subscriptions, sub)
+		}
+	}
+	ws := m.ws
+	m.lock.Unlock()
+	if ws == nil {
+		return nil
+	}
+	for _, sub := range subscriptions {
+		if err := ws.subscribeTo(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove forgets the subscriptions and unsubscribes if connected.
+func (m *ManagedWebSocket) remove(subscriptions ...subscription) error {
+	m.lock.Lock()
+	for _, sub := range subscriptions {
+		for i := range m.subscriptions {
+			if m.subscriptions[i].channelKey() == sub.channelKey() {
+				m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+				break
+			}
+		}
+		delete(m.channelIDs, sub.channelKey())
+	}
+	ws := m.ws
+	m.lock.Unlock()
+	if ws == nil {
+		return nil
+	}
+	for _, sub := range subscriptions {
+		if err := ws.unsubscribeFrom(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// has returns true if an equivalent subscription is recorded, treating a
+// book of depth 0 as the default depth.
+func (m *ManagedWebSocket) has(sub subscription) bool {
+	for _, existing := range m.subscriptions {
+		if existing.channelKey() == sub.channelKey() {
+			return true
+		}
+	}
+	return false
+}
+
+// channelKey returns the subscription as it is described in a
+// subscriptionStatus event.
+func (s subscription) channelKey() subscription {
+	if s.name == "book" && s.depth == 0 {
+		s.depth = defaultBookDepth
+	}
+	return s
+}
+
+func pairSubscriptions(name string, interval Interval, depth int, tickers []string) []subscription {
+	result := []subscription{}
+	for _, ticker := range tickers {
+		result = append(result, subscription{
+			name:     name,
+			pair:     ticker,
+			interval: interval,
+			depth:    depth,
+		})
+	}
+	return result
+}
+
+func (m *ManagedWebSocket) SubscribeTicker(tickers ...string) error {
+	return m.add(pairSubscriptions("ticker", 0, 0, tickers)...)
+}
+
+func (m *ManagedWebSocket) SubscribeOHLC(interval Interval, tickers ...string) error {
+	return m.add(pairSubscriptions("ohlc", interval, 0, tickers)...)
+}
+
+func (m *ManagedWebSocket) SubscribeSpread(tickers ...string) error {
+	return m.add(pairSubscriptions("spread", 0, 0, tickers)...)
+}
+
+func (m *ManagedWebSocket) SubscribeTrade(tickers ...string) error {
+	return m.add(pairSubscriptions("trade", 0, 0, tickers)...)
+}
+
+func (m *ManagedWebSocket) SubscribeBook(ticker string) error {
+	return m.add(pairSubscriptions("book", 0, 0, []string{ticker})...)
+}
+
+func (m *ManagedWebSocket) SubscribeBookDepth(depth int, tickers ...string) error {
+	return m.add(pairSubscriptions("book", 0, depth, tickers)...)
+}
+
+func (m *ManagedWebSocket) SubscribeOwnTrades() error {
+	return m.add(subscription{name: "ownTrades"})
+}
+
+func (m *ManagedWebSocket) SubscribeOpenOrders() error {
+	return m.add(subscription{name: "openOrders"})
+}
+
+func (m *ManagedWebSocket) UnsubscribeTicker(tickers ...string) error {
+	return m.remove(pairSubscriptions("ticker", 0, 0, tickers)...)
+}
+
+func (m *ManagedWebSocket) UnsubscribeOHLC(interval Interval, tickers ...string) error {
+	return m.remove(pairSubscriptions("ohlc", interval, 0, tickers)...)
+}
+
+func (m *ManagedWebSocket) UnsubscribeSpread(tickers ...string) error {
+	return m.remove(pairSubscriptions("spread", 0, 0, tickers)...)
+}
+
+func (m *ManagedWebSocket) UnsubscribeTrade(tickers ...string) error {
+	return m.remove(pairSubscriptions("trade", 0, 0, tickers)...)
+}
+
+func (m *ManagedWebSocket) UnsubscribeBook(depth int, tickers ...string) error {
+	return m.remove(pairSubscriptions("book", 0, depth, tickers)...)
+}
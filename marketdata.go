@@ -0,0 +1,371 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// publicResponse is a public endpoint response with the result decoded
+// generically, preserving numbers as json.Number.
+type publicResponse struct {
+	Error  []interface{}          `json:"error"`
+	Result map[string]interface{} `json:"result"`
+}
+
+func (c *RestClient) getPublic(path string, params map[string]interface{}) (*publicResponse, error) {
+	httpResponse, err := c.GetWithParams(path, params)
+	if err != nil {
+		return nil, RequestError{
+			NetworkError: err,
+		}
+	}
+	if httpResponse.StatusCode != 200 {
+		return nil, RequestError{
+			HttpError: fmt.Errorf("%s", httpResponse.Status),
+		}
+	}
+	body, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, RequestError{
+			NetworkError: err,
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var response publicResponse
+	if err := decoder.Decode(&response); err != nil {
+		return nil, RequestError{
+			DecodeError: err,
+		}
+	}
+	return &response, nil
+}
+
+// pairResult returns the entry for the single pair in a result that also
+// has a "last" cursor.
+func (r *publicResponse) pairResult() (string, []interface{}, error) {
+	for key, value := range r.Result {
+		if key == "last" {
+			continue
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return "", nil, fmt.Errorf("invalid result for pair %s", key)
+		}
+		return key, items, nil
+	}
+	return "", nil, fmt.Errorf("no pair in result")
+}
+
+type TickerResponse struct {
+	Error  []interface{}
+	Result map[string]*Ticker
+}
+
+func (r *TickerResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// Ticker gets the ticker for one or more pairs. The result is keyed by the
+// pair name used by the REST API.
+func (c *RestClient) Ticker(pairs ...string) (*TickerResponse, error) {
+	params := map[string]interface{}{
+		"pair": strings.Join(pairs, ","),
+	}
+	response, err := c.getPublic("/0/public/Ticker", params)
+	if err != nil {
+		return nil, err
+	}
+	tickerResponse := &TickerResponse{
+		Error:  response.Error,
+		Result: map[string]*Ticker{},
+	}
+	for pair, value := range response.Result {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, decodeError(fmt.Errorf("invalid ticker for pair %s", pair))
+		}
+		ticker, err := DecodeTicker(data)
+		if err != nil {
+			return nil, decodeError(err)
+		}
+		ticker.Pair = pair
+		tickerResponse.Result[pair] = ticker
+	}
+	return tickerResponse, nil
+}
+
+type OHLCResponse struct {
+	Error  []interface{}
+	Result struct {
+		Pair string
+		OHLC []*OHLC
+
+		// Last is the ID to use as since to get new data.
+		Last int64
+	}
+}
+
+func (r *OHLCResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// OHLC gets the OHLC data for a pair. If since is non-zero only data after
+// since is returned.
+func (c *RestClient) OHLC(pair string, interval Interval, since int64) (*OHLCResponse, error) {
+	params := map[string]interface{}{
+		"pair":     pair,
+		"interval": int(interval),
+	}
+	if since > 0 {
+		params["since"] = since
+	}
+	response, err := c.getPublic("/0/public/OHLC", params)
+	if err != nil {
+		return nil, err
+	}
+	ohlcResponse := &OHLCResponse{
+		Error: response.Error,
+	}
+	if ohlcResponse.HasError() {
+		return ohlcResponse, nil
+	}
+	key, items, err := response.pairResult()
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	ohlcResponse.Result.Pair = key
+	for _, item := range items {
+		values, ok := item.([]interface{})
+		if !ok {
+			return nil, decodeError(fmt.Errorf("invalid type for ohlc"))
+		}
+		ohlc, err := decodeRestOHLC(values, interval)
+		if err != nil {
+			return nil, decodeError(err)
+		}
+		ohlc.Pair = key
+		ohlcResponse.Result.OHLC = append(ohlcResponse.Result.OHLC, ohlc)
+	}
+	if ohlcResponse.Result.Last, err = parseInt(response.Result["last"]); err != nil {
+		return nil, decodeError(err)
+	}
+	return ohlcResponse, nil
+}
+
+// decodeRestOHLC decodes an OHLC entry from the REST API, which unlike the
+// websocket API has no end time.
+func decodeRestOHLC(data []interface{}, interval Interval) (*OHLC, error) {
+	var err error = nil
+	var ohlc *OHLC = &OHLC{}
+	if len(data) < 8 {
+		return nil, fmt.Errorf("not enough items")
+	}
+	if ohlc.Time, err = parseFloat(data[0]); err != nil {
+		return nil, err
+	}
+	ohlc.EndTime = ohlc.Time + float64(interval)*60
+	if ohlc.Open, err = parseDecimal(data[1]); err != nil {
+		return nil, err
+	}
+	if ohlc.High, err = parseDecimal(data[2]); err != nil {
+		return nil, err
+	}
+	if ohlc.Low, err = parseDecimal(data[3]); err != nil {
+		return nil, err
+	}
+	if ohlc.Close, err = parseDecimal(data[4]); err != nil {
+		return nil, err
+	}
+	if ohlc.VWAP, err = parseDecimal(data[5]); err != nil {
+		return nil, err
+	}
+	if ohlc.Volume, err = parseDecimal(data[6]); err != nil {
+		return nil, err
+	}
+	if ohlc.Count, err = parseInt(data[7]); err != nil {
+		return nil, err
+	}
+	return ohlc, nil
+}
+
+type DepthResponse struct {
+	Error  []interface{}
+	Result struct {
+		Pair string
+		Asks []BookLevel
+		Bids []BookLevel
+	}
+}
+
+func (r *DepthResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// Depth gets the order book for a pair. If count is non-zero at most count
+// levels are returned for each side.
+func (c *RestClient) Depth(pair string, count int) (*DepthResponse, error) {
+	params := map[string]interface{}{
+		"pair": pair,
+	}
+	if count > 0 {
+		params["count"] = count
+	}
+	response, err := c.getPublic("/0/public/Depth", params)
+	if err != nil {
+		return nil, err
+	}
+	depthResponse := &DepthResponse{
+		Error: response.Error,
+	}
+	for key, value := range response.Result {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, decodeError(fmt.Errorf("invalid depth for pair %s", key))
+		}
+		depthResponse.Result.Pair = key
+		if depthResponse.Result.Asks, err = decodeBookLevels(data["asks"]); err != nil {
+			return nil, decodeError(err)
+		}
+		if depthResponse.Result.Bids, err = decodeBookLevels(data["bids"]); err != nil {
+			return nil, decodeError(err)
+		}
+		break
+	}
+	return depthResponse, nil
+}
+
+type TradesResponse struct {
+	Error  []interface{}
+	Result struct {
+		Pair   string
+		Trades []Trade
+
+		// Last is the ID to use as since to get new trades.
+		Last string
+	}
+}
+
+func (r *TradesResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// Trades gets the recent trades for a pair. If since is not empty only
+// trades after since are returned.
+func (c *RestClient) Trades(pair string, since string) (*TradesResponse, error) {
+	params := map[string]interface{}{
+		"pair": pair,
+	}
+	if since != "" {
+		params["since"] = since
+	}
+	response, err := c.getPublic("/0/public/Trades", params)
+	if err != nil {
+		return nil, err
+	}
+	tradesResponse := &TradesResponse{
+		Error: response.Error,
+	}
+	if tradesResponse.HasError() {
+		return tradesResponse, nil
+	}
+	key, items, err := response.pairResult()
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	tradesResponse.Result.Pair = key
+	if tradesResponse.Result.Trades, err = DecodeTrades(items); err != nil {
+		return nil, decodeError(err)
+	}
+	for i := range tradesResponse.Result.Trades {
+		tradesResponse.Result.Trades[i].Pair = key
+	}
+	if last, ok := response.Result["last"].(string); ok {
+		tradesResponse.Result.Last = last
+	}
+	return tradesResponse, nil
+}
+
+type SpreadResponse struct {
+	Error  []interface{}
+	Result struct {
+		Pair    string
+		Spreads []*Spread
+
+		// Last is the ID to use as since to get new data.
+		Last int64
+	}
+}
+
+func (r *SpreadResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// Spread gets the recent spreads for a pair. If since is non-zero only
+// data after since is returned.
+func (c *RestClient) Spread(pair string, since int64) (*SpreadResponse, error) {
+	params := map[string]interface{}{
+		"pair": pair,
+	}
+	if since > 0 {
+		params["since"] = since
+	}
+	response, err := c.getPublic("/0/public/Spread", params)
+	if err != nil {
+		return nil, err
+	}
+	spreadResponse := &SpreadResponse{
+		Error: response.Error,
+	}
+	if spreadResponse.HasError() {
+		return spreadResponse, nil
+	}
+	key, items, err := response.pairResult()
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	spreadResponse.Result.Pair = key
+	for _, item := range items {
+		values, ok := item.([]interface{})
+		if !ok || len(values) < 3 {
+			return nil, decodeError(fmt.Errorf("invalid spread"))
+		}
+		// The REST API puts the time first.
+		spread, err := DecodeSpread([]interface{}{values[1], values[2], values[0]})
+		if err != nil {
+			return nil, decodeError(err)
+		}
+		spread.Pair = key
+		spreadResponse.Result.Spreads = append(spreadResponse.Result.Spreads, spread)
+	}
+	if spreadResponse.Result.Last, err = parseInt(response.Result["last"]); err != nil {
+		return nil, decodeError(err)
+	}
+	return spreadResponse, nil
+}
@@ -0,0 +1,199 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import "strings"
+
+// Values of Order.Status.
+const (
+	OrderStatusPending  = "pending"
+	OrderStatusOpen     = "open"
+	OrderStatusClosed   = "closed"
+	OrderStatusCanceled = "canceled"
+	OrderStatusExpired  = "expired"
+)
+
+type OrderDescription struct {
+	Pair      string            `json:"pair"`
+	Side      OrderSide         `json:"type"`
+	OrderType AddOrderOrderType `json:"ordertype"`
+	Price     Decimal           `json:"price"`
+	Price2    Decimal           `json:"price2"`
+	Leverage  string            `json:"leverage"`
+	Order     string            `json:"order"`
+	Close     string            `json:"close"`
+}
+
+// Order is an order as returned by the REST API.
+type Order struct {
+	TxID        string           // Not in JSON response.
+	RefID       string           `json:"refid"`
+	UserRef     int64            `json:"userref"`
+	Status      string           `json:"status"`
+	Reason      string           `json:"reason"`
+	OpenTime    float64          `json:"opentm"`
+	StartTime   float64          `json:"starttm"`
+	ExpireTime  float64          `json:"expiretm"`
+	CloseTime   float64          `json:"closetm"`
+	Description OrderDescription `json:"descr"`
+	Volume      Decimal          `json:"vol"`
+	VolumeExec  Decimal          `json:"vol_exec"`
+	Cost        Decimal          `json:"cost"`
+	Fee         Decimal          `json:"fee"`
+	AvgPrice    Decimal          `json:"price"`
+	StopPrice   Decimal          `json:"stopprice"`
+	LimitPrice  Decimal          `json:"limitprice"`
+	Misc        string           `json:"misc"`
+	OFlags      string           `json:"oflags"`
+
+	// IDs of the trades of the order, only set if trades were requested.
+	Trades []string `json:"trades"`
+}
+
+func setOrderTxIDs(orders map[string]*Order) {
+	for txid, order := range orders {
+		order.TxID = txid
+	}
+}
+
+type OpenOrdersResponse struct {
+	Error  []interface{} `json:"error"`
+	Result struct {
+		Open map[string]*Order `json:"open"`
+	} `json:"result"`
+}
+
+func (r *OpenOrdersResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// OpenOrders gets the open orders. If userRef is non-zero only orders with
+// that user reference are returned.
+func (c *RestClient) OpenOrders(trades bool, userRef int32) (*OpenOrdersResponse, error) {
+	params := map[string]interface{}{}
+	if trades {
+		params["trades"] = true
+	}
+	if userRef != 0 {
+		params["userref"] = userRef
+	}
+	var response OpenOrdersResponse
+	if err := c.postAndDecode("/0/private/OpenOrders", params, &response); err != nil {
+		return nil, err
+	}
+	setOrderTxIDs(response.Result.Open)
+	return &response, nil
+}
+
+// Values of ClosedOrdersRequest.CloseTime.
+const (
+	CloseTimeOpen  = "open"
+	CloseTimeClose = "close"
+	CloseTimeBoth  = "both"
+)
+
+type ClosedOrdersRequest struct {
+	Trades  bool
+	UserRef int32
+
+	// Start and End are exclusive bounds, given as either a unix
+	// timestamp or an order txid.
+	Start string
+	End   string
+
+	// Ofs is the offset of the first result, for paging.
+	Ofs int
+
+	// CloseTime is which time to use for Start and End, defaulting to
+	// both.
+	CloseTime string
+}
+
+type ClosedOrdersResponse struct {
+	Error  []interface{} `json:"error"`
+	Result struct {
+		Closed map[string]*Order `json:"closed"`
+
+		// Count is the total number of orders matching the request.
+		Count int64 `json:"count"`
+	} `json:"result"`
+}
+
+func (r *ClosedOrdersResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// ClosedOrders gets closed orders, 50 at a time, most recent first.
+func (c *RestClient) ClosedOrders(request ClosedOrdersRequest) (*ClosedOrdersResponse, error) {
+	params := map[string]interface{}{}
+	if request.Trades {
+		params["trades"] = true
+	}
+	if request.UserRef != 0 {
+		params["userref"] = request.UserRef
+	}
+	if request.Start != "" {
+		params["start"] = request.Start
+	}
+	if request.End != "" {
+		params["end"] = request.End
+	}
+	if request.Ofs > 0 {
+		params["ofs"] = request.Ofs
+	}
+	if request.CloseTime != "" {
+		params["closetime"] = request.CloseTime
+	}
+	var response ClosedOrdersResponse
+	if err := c.postAndDecode("/0/private/ClosedOrders", params, &response); err != nil {
+		return nil, err
+	}
+	setOrderTxIDs(response.Result.Closed)
+	return &response, nil
+}
+
+type QueryOrdersResponse struct {
+	Error  []interface{}     `json:"error"`
+	Result map[string]*Order `json:"result"`
+}
+
+func (r *QueryOrdersResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// QueryOrders gets orders by txid. At most 50 txids may be given.
+func (c *RestClient) QueryOrders(trades bool, txIds ...string) (*QueryOrdersResponse, error) {
+	params := map[string]interface{}{
+		"txid": strings.Join(txIds, ","),
+	}
+	if trades {
+		params["trades"] = true
+	}
+	var response QueryOrdersResponse
+	if err := c.postAndDecode("/0/private/QueryOrders", params, &response); err != nil {
+		return nil, err
+	}
+	setOrderTxIDs(response.Result)
+	return &response, nil
+}
@@ -0,0 +1,265 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import "fmt"
+
+// RoundingMode selects how AddOrder rounds prices to the precision of a
+// pair.
+type RoundingMode string
+
+// RoundingPassive rounds absolute prices away from execution: limit and
+// take-profit prices down for buys and up for sells, stop prices up for buys
+// and down for sells. Relative prices are rounded to nearest. Volumes are
+// rounded down.
+const RoundingPassive RoundingMode = "passive"
+
+// RoundingNearest rounds prices and volumes to the nearest value.
+const RoundingNearest RoundingMode = "nearest"
+
+// RoundingFloor rounds prices and volumes down.
+const RoundingFloor RoundingMode = "floor"
+
+// DefaultRoundingTolerance is the largest relative change rounding may make
+// to a price or volume before the order is rejected.
+const DefaultRoundingTolerance = 0.001
+
+// RoundingError is returned when rounding an order to the precision of its
+// pair changes a price or volume by more than the rounding tolerance.
+type RoundingError struct {
+	Field   string
+	Value   Decimal
+	Rounded Decimal
+}
+
+func (e RoundingError) Error() string {
+	return fmt.Sprintf("rounding %s %v to %v changes the order", e.Field,
+		e.Value, e.Rounded)
+}
+
+// orderRounding holds the rounding settings of a client.
+type orderRounding struct {
+	pairs     *AssetPairService
+	mode      RoundingMode
+	tolerance float64
+}
+
+// round rounds the order if an AssetPairService is set. The service is not
+// refreshed, so a pair it has not loaded is an error.
+func (r orderRounding) round(order AddOrderRequest) (AddOrderRequest, error) {
+	if r.pairs == nil {
+		return order, nil
+	}
+	return RoundOrder(order, r.pairs, r.mode, r.tolerance)
+}
+
+// SetAssetPairService sets the asset pairs AddOrder uses to round prices
+// and volumes to the precision of the pair. The service must already be
+// loaded; orders for pairs it does not have are rejected. Without one,
+// prices and volumes are rounded to 8 decimals.
+func (c *RestClient) SetAssetPairService(pairs *AssetPairService) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.rounding.pairs = pairs
+}
+
+// SetRoundingMode sets how AddOrder rounds prices, defaulting to
+// RoundingPassive.
+func (c *RestClient) SetRoundingMode(mode RoundingMode) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.rounding.mode = mode
+}
+
+// SetRoundingTolerance sets the largest relative change rounding may make
+// before AddOrder rejects the order, defaulting to
+// DefaultRoundingTolerance.
+func (c *RestClient) SetRoundingTolerance(tolerance float64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.rounding.tolerance = tolerance
+}
+
+func (c *RestClient) orderRounding() orderRounding {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.rounding
+}
+
+// SetAssetPairService sets the asset pairs AddOrder uses to round prices
+// and volumes, as with RestClient.SetAssetPairService.
+func (s *WebSocket) SetAssetPairService(pairs *AssetPairService) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.rounding.pairs = pairs
+}
+
+// SetRoundingMode sets how AddOrder rounds prices, defaulting to
+// RoundingPassive.
+func (s *WebSocket) SetRoundingMode(mode RoundingMode) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.rounding.mode = mode
+}
+
+// SetRoundingTolerance sets the largest relative change rounding may make
+// before AddOrder rejects the order, defaulting to
+// DefaultRoundingTolerance.
+func (s *WebSocket) SetRoundingTolerance(tolerance float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.rounding.tolerance = tolerance
+}
+
+func (s *WebSocket) orderRounding() orderRounding {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.rounding
+}
+
+// RoundOrder returns a copy of the order with its prices and volume
+// rounded to the precision of its pair.
+func RoundOrder(order AddOrderRequest, pairs *AssetPairService,
+	mode RoundingMode, tolerance float64) (AddOrderRequest, error) {
+	info := pairs.GetInfo(order.Pair)
+	if info == nil {
+		return order, fmt.Errorf("unknown pair: %s", order.Pair)
+	}
+	if mode == "" {
+		mode = RoundingPassive
+	}
+	switch mode {
+	case RoundingPassive, RoundingNearest, RoundingFloor:
+	default:
+		return order, fmt.Errorf("invalid rounding mode: %s", mode)
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultRoundingTolerance
+	}
+
+	rounder := orderRounder{
+		mode:      mode,
+		tolerance: NewDecimalFromFloat(tolerance),
+	}
+	priceDecimals := int32(info.PairDecimals)
+	lotDecimals := int32(info.LotDecimals)
+
+	var err error
+	if order.Type.usesPrice() && !order.PricePercent {
+		dir := rounder.priceDirection(order.Side, order.Type, order.PriceOffset, false)
+		if order.Price, err = rounder.round("price", order.Price, priceDecimals, dir); err != nil {
+			return order, err
+		}
+	}
+	if order.Type.usesPrice2() && !order.Price2Percent {
+		dir := rounder.priceDirection(order.Side, order.Type, order.Price2Offset, true)
+		if order.Price2, err = rounder.round("price2", order.Price2, priceDecimals, dir); err != nil {
+			return order, err
+		}
+	}
+
+	if order.Volume.Sign() > 0 {
+		dir := roundDown
+		if mode == RoundingNearest {
+			dir = roundNearest
+		}
+		// With viqc the volume is in the quote currency, so it has the
+		// precision of a cost and ordermin does not apply.
+		viqc := order.hasFlag(OrderFlagVolumeInQuote)
+		volumeDecimals := lotDecimals
+		if viqc {
+			volumeDecimals = int32(info.CostDecimals)
+		}
+		volume, err := rounder.round("volume", order.Volume, volumeDecimals, dir)
+		if err != nil {
+			return order, err
+		}
+		if !viqc && volume.LessThan(info.OrderMin) && !order.Volume.LessThan(info.OrderMin) {
+			return order, RoundingError{Field: "volume", Value: order.Volume, Rounded: volume}
+		}
+		order.Volume = volume
+	}
+
+	if order.Close != nil {
+		// The close order trades on the opposite side.
+		side := OrderSideSell
+		if order.Side == OrderSideSell {
+			side = OrderSideBuy
+		}
+		closeOrder := *order.Close
+		if closeOrder.Type.usesPrice() && !closeOrder.PricePercent {
+			dir := rounder.priceDirection(side, closeOrder.Type, closeOrder.PriceOffset, false)
+			if closeOrder.Price, err = rounder.round("close price", closeOrder.Price, priceDecimals, dir); err != nil {
+				return order, err
+			}
+		}
+		if closeOrder.Type.usesPrice2() && !closeOrder.Price2Percent {
+			dir := rounder.priceDirection(side, closeOrder.Type, closeOrder.Price2Offset, true)
+			if closeOrder.Price2, err = rounder.round("close price2", closeOrder.Price2, priceDecimals, dir); err != nil {
+				return order, err
+			}
+		}
+		order.Close = &closeOrder
+	}
+
+	return order, nil
+}
+
+type orderRounder struct {
+	mode      RoundingMode
+	tolerance Decimal
+}
+
+// priceDirection returns the direction to round a price in. The limit
+// argument is true for the limit price of stop and take-profit limit
+// orders.
+func (r orderRounder) priceDirection(side OrderSide, orderType AddOrderOrderType,
+	offset PriceOffset, limit bool) roundDirection {
+	switch r.mode {
+	case RoundingFloor:
+		return roundDown
+	case RoundingNearest:
+		return roundNearest
+	}
+	if offset != PriceAbsolute {
+		return roundNearest
+	}
+	stop := !limit && (orderType == OrderTypeStopLoss || orderType == OrderTypeStopLossLimit)
+	if (side == OrderSideBuy) != stop {
+		return roundDown
+	}
+	return roundUp
+}
+
+// round rounds a value to the given decimals, failing if the relative
+// change is more than the tolerance.
+func (r orderRounder) round(field string, value Decimal, decimals int32,
+	dir roundDirection) (Decimal, error) {
+	rounded := value.round(decimals, dir)
+	change := rounded.Sub(value).Abs()
+	if rounded.Sign() <= 0 || change.GreaterThan(value.Mul(r.tolerance)) {
+		return value, RoundingError{Field: field, Value: value, Rounded: rounded}
+	}
+	return rounded, nil
+}
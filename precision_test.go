@@ -0,0 +1,158 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const testAssetPairs = `{"error":[],"result":{` +
+	`"XXBTZUSD":{"altname":"XBTUSD","wsname":"XBT/USD","pair_decimals":1,` +
+	`"lot_decimals":8,"cost_decimals":5,"ordermin":"0.0001"},` +
+	`"TESTUSD":{"altname":"TESTUSD","wsname":"TEST/USD","pair_decimals":2,` +
+	`"lot_decimals":4,"cost_decimals":2,"ordermin":"1.00005"}}}`
+
+func testAssetPairService(t *testing.T) *AssetPairService {
+	var response AssetPairResponse
+	if err := json.Unmarshal([]byte(testAssetPairs), &response); err != nil {
+		t.Fatal(err)
+	}
+	pairs := NewAssetPairService()
+	pairs.LoadResponse(response)
+	return pairs
+}
+
+func TestRoundOrder(t *testing.T) {
+	pairs := testAssetPairService(t)
+
+	limit := func(side OrderSide, price string) AddOrderRequest {
+		return AddOrderRequest{
+			Pair:   "XBTUSD",
+			Side:   side,
+			Type:   OrderTypeLimit,
+			Price:  MustParseDecimal(price),
+			Volume: MustParseDecimal("1"),
+		}
+	}
+	stop := func(side OrderSide, price string) AddOrderRequest {
+		order := limit(side, price)
+		order.Type = OrderTypeStopLoss
+		return order
+	}
+	withClose := func(order AddOrderRequest, price string) AddOrderRequest {
+		order.Close = &CloseOrder{
+			Type:  OrderTypeLimit,
+			Price: MustParseDecimal(price),
+		}
+		return order
+	}
+	market := func(side OrderSide) AddOrderRequest {
+		return AddOrderRequest{
+			Pair:   "XBTUSD",
+			Side:   side,
+			Type:   OrderTypeMarket,
+			Volume: MustParseDecimal("1"),
+		}
+	}
+	withVolume := func(order AddOrderRequest, volume string, flags ...OrderFlag) AddOrderRequest {
+		order.Pair = "TESTUSD"
+		order.Volume = MustParseDecimal(volume)
+		order.Flags = flags
+		return order
+	}
+
+	tests := []struct {
+		name       string
+		order      AddOrderRequest
+		mode       RoundingMode
+		tolerance  float64
+		price      string
+		closePrice string
+		volume     string
+		err        bool
+	}{
+		{name: "passive buy rounds down", order: limit(OrderSideBuy, "50000.04"),
+			price: "50000.0"},
+		{name: "passive sell rounds up", order: limit(OrderSideSell, "50000.04"),
+			price: "50000.1"},
+		{name: "nearest buy", order: limit(OrderSideBuy, "50000.06"),
+			mode: RoundingNearest, price: "50000.1"},
+		{name: "nearest sell", order: limit(OrderSideSell, "50000.04"),
+			mode: RoundingNearest, price: "50000.0"},
+		{name: "floor sell", order: limit(OrderSideSell, "50000.06"),
+			mode: RoundingFloor, price: "50000.0"},
+		{name: "passive buy stop rounds up", order: stop(OrderSideBuy, "50000.04"),
+			price: "50000.1"},
+		{name: "passive sell stop rounds down", order: stop(OrderSideSell, "50000.06"),
+			price: "50000.0"},
+		{name: "close sells above a buy",
+			order: withClose(limit(OrderSideBuy, "50000.04"), "51000.04"),
+			price: "50000.0", closePrice: "51000.1"},
+		{name: "close buys below a sell",
+			order: withClose(limit(OrderSideSell, "50000.04"), "49000.06"),
+			price: "50000.1", closePrice: "49000.0"},
+		{name: "outside default tolerance", order: limit(OrderSideBuy, "10.04"),
+			err: true},
+		{name: "within tolerance", order: limit(OrderSideBuy, "10.04"),
+			tolerance: 0.01, price: "10.0"},
+		{name: "volume rounds down", order: withVolume(limit(OrderSideBuy, "10"), "2.00009"),
+			volume: "2.0000"},
+		{name: "volume rounded under ordermin",
+			order: withVolume(limit(OrderSideBuy, "10"), "1.00005"),
+			err:   true},
+		{name: "viqc volume uses cost decimals without ordermin",
+			order:  withVolume(market(OrderSideBuy), "0.50009", OrderFlagVolumeInQuote),
+			volume: "0.50"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.order.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			rounded, err := RoundOrder(test.order, pairs, test.mode, test.tolerance)
+			if test.err {
+				if _, ok := err.(RoundingError); !ok {
+					t.Fatalf("expected a RoundingError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.price != "" && !rounded.Price.Equal(MustParseDecimal(test.price)) {
+				t.Errorf("price: expected %s, got %v", test.price, rounded.Price)
+			}
+			if test.closePrice != "" &&
+				!rounded.Close.Price.Equal(MustParseDecimal(test.closePrice)) {
+				t.Errorf("close price: expected %s, got %v", test.closePrice,
+					rounded.Close.Price)
+			}
+			if test.volume != "" && !rounded.Volume.Equal(MustParseDecimal(test.volume)) {
+				t.Errorf("volume: expected %s, got %v", test.volume, rounded.Volume)
+			}
+		})
+	}
+}
@@ -0,0 +1,501 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SubscribeOwnTrades subscribes to the trades of the authenticated user.
+// Only available on a private WebSocket.
+func (s *WebSocket) SubscribeOwnTrades() error {
+	return s.subscribePrivate("ownTrades")
+}
+
+// SubscribeOpenOrders subscribes to the open orders of the authenticated
+// user. Only available on a private WebSocket.
+func (s *WebSocket) SubscribeOpenOrders() error {
+	return s.subscribePrivate("openOrders")
+}
+
+func (s *WebSocket) subscribePrivate(name string) error {
+	if s.token == "" {
+		return fmt.Errorf("%s requires a private websocket", name)
+	}
+	message := SubscribeMessage{
+		Event: "subscribe",
+		Subscription: map[string]interface{}{
+			"name":  name,
+			"token": s.token,
+		},
+	}
+	return s.subscribe(message)
+}
+
+// OwnTrade is a trade made by the authenticated user.
+type OwnTrade struct {
+	TradeID   string
+	OrderTxID string
+	PosTxID   string
+	Pair      string
+	Time      float64
+	Side      OrderSide
+	OrderType AddOrderOrderType
+	Price     Decimal
+	Cost      Decimal
+	Fee       Decimal
+	Volume    Decimal
+	Margin    Decimal
+}
+
+// OwnTrades is a decoded ownTrades event. The first event after
+// subscribing contains a snapshot of the most recent trades.
+type OwnTrades struct {
+	Sequence int64
+	Trades   []OwnTrade
+}
+
+// OpenOrderDescription describes an open order.
+type OpenOrderDescription struct {
+	Pair      string
+	Side      OrderSide
+	OrderType AddOrderOrderType
+	Price     Decimal
+	Price2    Decimal
+	Leverage  string
+	Order     string
+	Close     string
+}
+
+// OpenOrder is the state of an order of the authenticated user. After the
+// initial snapshot, updates only carry the fields that changed, usually
+// just the status.
+type OpenOrder struct {
+	TxID        string
+	RefID       string
+	UserRef     int64
+	Status      string
+	OpenTime    float64
+	StartTime   float64
+	ExpireTime  float64
+	Description OpenOrderDescription
+	Volume      Decimal
+	VolumeExec  Decimal
+	Cost        Decimal
+	Fee         Decimal
+	AvgPrice    Decimal
+	StopPrice   Decimal
+	LimitPrice  Decimal
+	Misc        string
+	OFlags      string
+}
+
+// OpenOrders is a decoded openOrders event.
+type OpenOrders struct {
+	Sequence int64
+	Orders   []OpenOrder
+}
+
+func decodePrivate(name string, decoded []interface{}) (interface{}, error) {
+	items := decoded[0].([]interface{})
+
+	var sequence int64
+	if len(decoded) > 2 {
+		if meta, ok := decoded[2].(map[string]interface{}); ok {
+			if value, ok := meta["sequence"].(json.Number); ok {
+				sequence, _ = value.Int64()
+			}
+		}
+	}
+
+	switch name {
+	case "ownTrades":
+		trades := &OwnTrades{
+			Sequence: sequence,
+		}
+		err := forEachKeyed(items, func(id string, data map[string]interface{}) error {
+			trade, err := DecodeOwnTrade(data)
+			if err != nil {
+				return err
+			}
+			trade.TradeID = id
+			trades.Trades = append(trades.Trades, *trade)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return trades, nil
+	case "openOrders":
+		orders := &OpenOrders{
+			Sequence: sequence,
+		}
+		err := forEachKeyed(items, func(id string, data map[string]interface{}) error {
+			order, err := DecodeOpenOrder(data)
+			if err != nil {
+				return err
+			}
+			order.TxID = id
+			orders.Orders = append(orders.Orders, *order)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return orders, nil
+	default:
+		return nil, fmt.Errorf("unknown channel type: %s", name)
+	}
+}
+
+// forEachKeyed calls fn for each entry of a list of single entry objects
+// keyed by ID, the format used by the private feeds.
+func forEachKeyed(items []interface{}, fn func(string, map[string]interface{}) error) error {
+	for _, item := range items {
+		entries, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid type for private feed entry")
+		}
+		for id, entry := range entries {
+			data, ok := entry.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid type for private feed entry: %s", id)
+			}
+			if err := fn(id, data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DecodeOwnTrade decodes an ownTrades entry into an OwnTrade.
+func DecodeOwnTrade(data map[string]interface{}) (*OwnTrade, error) {
+	var err error = nil
+	var trade *OwnTrade = &OwnTrade{}
+	trade.OrderTxID, _ = data["ordertxid"].(string)
+	trade.PosTxID, _ = data["postxid"].(string)
+	trade.Pair, _ = data["pair"].(string)
+	if side, ok := data["type"].(string); ok {
+		trade.Side = OrderSide(side)
+	}
+	if orderType, ok := data["ordertype"].(string); ok {
+		trade.OrderType = AddOrderOrderType(orderType)
+	}
+	if trade.Time, err = parseOptionalFloat(data, "time"); err != nil {
+		return nil, err
+	}
+	for key, value := range map[string]*Decimal{
+		"price":  &trade.Price,
+		"cost":   &trade.Cost,
+		"fee":    &trade.Fee,
+		"vol":    &trade.Volume,
+		"margin": &trade.Margin,
+	} {
+		if *value, err = parseOptionalDecimal(data, key); err != nil {
+			return nil, err
+		}
+	}
+	return trade, nil
+}
+
+// DecodeOpenOrder decodes an openOrders entry into an OpenOrder. Fields
+// not present in the entry are left as their zero value.
+func DecodeOpenOrder(data map[string]interface{}) (*OpenOrder, error) {
+	var err error = nil
+	var order *OpenOrder = &OpenOrder{}
+	order.RefID, _ = data["refid"].(string)
+	order.Status, _ = data["status"].(string)
+	order.Misc, _ = data["misc"].(string)
+	order.OFlags, _ = data["oflags"].(string)
+	if userRef, ok := data["userref"]; ok && userRef != nil {
+		if order.UserRef, err = parseInt(userRef); err != nil {
+			return nil, err
+		}
+	}
+	for key, value := range map[string]*float64{
+		"opentm":   &order.OpenTime,
+		"starttm":  &order.StartTime,
+		"expiretm": &order.ExpireTime,
+	} {
+		if *value, err = parseOptionalFloat(data, key); err != nil {
+			return nil, err
+		}
+	}
+	for key, value := range map[string]*Decimal{
+		"vol":        &order.Volume,
+		"vol_exec":   &order.VolumeExec,
+		"cost":       &order.Cost,
+		"fee":        &order.Fee,
+		"avg_price":  &order.AvgPrice,
+		"stopprice":  &order.StopPrice,
+		"limitprice": &order.LimitPrice,
+	} {
+		if *value, err = parseOptionalDecimal(data, key); err != nil {
+			return nil, err
+		}
+	}
+	if descr, ok := data["descr"].(map[string]interface{}); ok {
+		order.Description.Pair, _ = descr["pair"].(string)
+		order.Description.Leverage, _ = descr["leverage"].(string)
+		order.Description.Order, _ = descr["order"].(string)
+		order.Description.Close, _ = descr["close"].(string)
+		if side, ok := descr["type"].(string); ok {
+			order.Description.Side = OrderSide(side)
+		}
+		if orderType, ok := descr["ordertype"].(string); ok {
+			order.Description.OrderType = AddOrderOrderType(orderType)
+		}
+		if order.Description.Price, err = parseOptionalDecimal(descr, "price"); err != nil {
+			return nil, err
+		}
+		if order.Description.Price2, err = parseOptionalDecimal(descr, "price2"); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// parseOptionalFloat parses the value of key in data, returning 0 if the
+// key is not present or null.
+func parseOptionalFloat(data map[string]interface{}, key string) (float64, error) {
+	value, ok := data[key]
+	if !ok || value == nil {
+		return 0, nil
+	}
+	if number, ok := value.(json.Number); ok {
+		return number.Float64()
+	}
+	result, err := parseFloat(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", key, err)
+	}
+	return result, nil
+}
+
+// parseOptionalDecimal parses the value of key in data, returning 0 if the
+// key is not present or null.
+func parseOptionalDecimal(data map[string]interface{}, key string) (Decimal, error) {
+	value, ok := data[key]
+	if !ok || value == nil {
+		return Decimal{}, nil
+	}
+	result, err := parseDecimal(value)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("%s: %v", key, err)
+	}
+	return result, nil
+}
+
+// AddOrderStatus is the response to an order added with
+// WebSocket.AddOrder.
+type AddOrderStatus struct {
+	Event        string `json:"event"`
+	RequestID    int64  `json:"reqid"`
+	Status       string `json:"status"`
+	TxID         string `json:"txid"`
+	Descr        string `json:"descr"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+func (r *AddOrderStatus) HasError() bool {
+	return r.Status == "error"
+}
+
+// CancelOrderStatus is the response to WebSocket.CancelOrder.
+type CancelOrderStatus struct {
+	Event        string `json:"event"`
+	RequestID    int64  `json:"reqid"`
+	Status       string `json:"status"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+func (r *CancelOrderStatus) HasError() bool {
+	return r.Status == "error"
+}
+
+// CancelAllStatus is the response to WebSocket.CancelAll.
+type CancelAllStatus struct {
+	Event        string `json:"event"`
+	RequestID    int64  `json:"reqid"`
+	Status       string `json:"status"`
+	Count        int64  `json:"count"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+func (r *CancelAllStatus) HasError() bool {
+	return r.Status == "error"
+}
+
+// pendingRequest is a request waiting on its status event. deliver passes
+// the decoded status to the caller, abort closes the caller's channel
+// without a result.
+type pendingRequest struct {
+	deliver func(interface{})
+	abort   func()
+}
+
+// AddOrder places an order over the websocket. The returned channel
+// receives the matching AddOrderStatus once it has been read with Decode,
+// or is closed without a value if the WebSocket is closed first.
+func (s *WebSocket) AddOrder(order AddOrderRequest) (<-chan *AddOrderStatus, error) {
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+	order, err := s.orderRounding().round(order)
+	if err != nil {
+		return nil, err
+	}
+	params := order.params()
+	if order.ValidateOnly {
+		params["validate"] = "true"
+	}
+	if userRef, ok := params["userref"]; ok {
+		params["userref"] = fmt.Sprintf("%v", userRef)
+	}
+	result := make(chan *AddOrderStatus, 1)
+	err = s.sendRequest("addOrder", params, pendingRequest{
+		deliver: func(status interface{}) {
+			if v, ok := status.(*AddOrderStatus); ok {
+				result <- v
+			}
+			close(result)
+		},
+		abort: func() { close(result) },
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CancelOrder cancels one or more orders by txid over the websocket.
+func (s *WebSocket) CancelOrder(txIds ...string) (<-chan *CancelOrderStatus, error) {
+	params := map[string]interface{}{
+		"txid": txIds,
+	}
+	result := make(chan *CancelOrderStatus, 1)
+	err := s.sendRequest("cancelOrder", params, pendingRequest{
+		deliver: func(status interface{}) {
+			if v, ok := status.(*CancelOrderStatus); ok {
+				result <- v
+			}
+			close(result)
+		},
+		abort: func() { close(result) },
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CancelAll cancels all open orders over the websocket.
+func (s *WebSocket) CancelAll() (<-chan *CancelAllStatus, error) {
+	result := make(chan *CancelAllStatus, 1)
+	err := s.sendRequest("cancelAll", map[string]interface{}{}, pendingRequest{
+		deliver: func(status interface{}) {
+			if v, ok := status.(*CancelAllStatus); ok {
+				result <- v
+			}
+			close(result)
+		},
+		abort: func() { close(result) },
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// sendRequest sends an event with a new reqid and registers it as pending
+// until its status arrives.
+func (s *WebSocket) sendRequest(event string, params map[string]interface{}, request pendingRequest) error {
+	if s.token == "" {
+		return fmt.Errorf("%s requires a private websocket", event)
+	}
+
+	reqID := s.nextRequestID()
+	s.lock.Lock()
+	if s.pending == nil {
+		s.pending = map[int64]pendingRequest{}
+	}
+	s.pending[reqID] = request
+	s.lock.Unlock()
+
+	params["event"] = event
+	params["token"] = s.token
+	params["reqid"] = reqID
+	if err := s.writeJSON(params); err != nil {
+		s.lock.Lock()
+		delete(s.pending, reqID)
+		s.lock.Unlock()
+		return err
+	}
+	return nil
+}
+
+// resolveRequest decodes a request status event and delivers it to the
+// caller waiting on its reqid, if any.
+func (s *WebSocket) resolveRequest(event string, reqID int64, input []byte) (interface{}, error) {
+	var status interface{}
+	switch event {
+	case "addOrderStatus":
+		status = &AddOrderStatus{}
+	case "cancelOrderStatus":
+		status = &CancelOrderStatus{}
+	case "cancelAllStatus":
+		status = &CancelAllStatus{}
+	default:
+		return nil, fmt.Errorf("unknown request status event: %s", event)
+	}
+	err := json.Unmarshal(input, status)
+
+	s.lock.Lock()
+	request, ok := s.pending[reqID]
+	delete(s.pending, reqID)
+	s.lock.Unlock()
+
+	if err != nil {
+		if ok {
+			request.abort()
+		}
+		return nil, err
+	}
+	if ok {
+		request.deliver(status)
+	}
+	return status, nil
+}
+
+func (s *WebSocket) abortRequests() {
+	s.lock.Lock()
+	pending := s.pending
+	s.pending = map[int64]pendingRequest{}
+	s.lock.Unlock()
+	for _, request := range pending {
+		request.abort()
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"sort"
 	"strings"
@@ -50,6 +51,13 @@ type RestClient struct {
 	apiSecret []byte
 	lastNonce int64
 	lock      sync.Mutex
+
+	// Withdrawal keys allowed by Withdraw, nil if not restricted.
+	withdrawKeys map[string]bool
+
+	// Settings used by AddOrder to round to the precision of a pair,
+	// guarded by lock.
+	rounding orderRounding
 }
 
 func NewRestClient(apiKey string, apiSecret string) (*RestClient, error) {
@@ -77,6 +85,14 @@ func (c *RestClient) Get(path string) (*http.Response, error) {
 	return http.DefaultClient.Do(request)
 }
 
+// GetWithParams performs a GET with the params encoded as the query string.
+func (c *RestClient) GetWithParams(path string, params map[string]interface{}) (*http.Response, error) {
+	if len(params) == 0 {
+		return c.Get(path)
+	}
+	return c.Get(fmt.Sprintf("%s?%s", path, c.buildQueryString(params)))
+}
+
 func (c *RestClient) Post(path string, params map[string]interface{}) (*http.Response, error) {
 	url := fmt.Sprintf("%s/%s", API_ROOT, strings.TrimPrefix(path, "/"))
 	if params == nil {
@@ -134,7 +150,8 @@ func (c *RestClient) buildQueryString(params map[string]interface{}) string {
 		if queryString != "" {
 			queryString = fmt.Sprintf("%s&", queryString)
 		}
-		queryString = fmt.Sprintf("%s%s=%v", queryString, key, params[key])
+		value := url.QueryEscape(fmt.Sprintf("%v", params[key]))
+		queryString = fmt.Sprintf("%s%s=%s", queryString, url.QueryEscape(key), value)
 	}
 
 	return queryString
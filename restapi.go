@@ -81,6 +81,50 @@ func (c *RestClient) CancelOrder(txId string) (*CancelOrderResponse, error) {
 	return &response, nil
 }
 
+type WebSocketsTokenResponse struct {
+	Error  []interface{} `json:"error"`
+	Result struct {
+		Token   string `json:"token"`
+		Expires int64  `json:"expires"`
+	} `json:"result"`
+}
+
+func (r *WebSocketsTokenResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// GetWebSocketsToken gets a token for subscribing to private feeds on the
+// authenticated websocket.
+func (c *RestClient) GetWebSocketsToken() (*WebSocketsTokenResponse, error) {
+	var response WebSocketsTokenResponse
+	if err := c.postAndDecode("/0/private/GetWebSocketsToken", nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// postAndDecode posts to a private endpoint and decodes the response into
+// v, returning any failure as a RequestError.
+func (c *RestClient) postAndDecode(path string, params map[string]interface{}, v interface{}) error {
+	httpResponse, err := c.Post(path, params)
+	if err != nil {
+		return RequestError{
+			NetworkError: err,
+		}
+	}
+	if httpResponse.StatusCode != 200 {
+		return RequestError{
+			HttpError: fmt.Errorf("%s", httpResponse.Status),
+		}
+	}
+	if err := decodeHttpResponse(httpResponse, v); err != nil {
+		return RequestError{
+			DecodeError: err,
+		}
+	}
+	return nil
+}
+
 type RequestError struct {
 	NetworkError error
 	HttpError    error
@@ -99,3 +143,9 @@ func (e RequestError) Error() string {
 		return "unknown error"
 	}
 }
+
+func decodeError(err error) error {
+	return RequestError{
+		DecodeError: err,
+	}
+}
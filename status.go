@@ -0,0 +1,148 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Values of SystemStatus.Status.
+const (
+	SystemStatusOnline      = "online"
+	SystemStatusMaintenance = "maintenance"
+	SystemStatusCancelOnly  = "cancel_only"
+	SystemStatusPostOnly    = "post_only"
+)
+
+// SystemStatus is sent on connect and whenever the status of the exchange
+// changes.
+type SystemStatus struct {
+	ConnectionID uint64 `json:"connectionID"`
+	Status       string `json:"status"`
+	Version      string `json:"version"`
+}
+
+// CanTrade returns true if new orders of any type may be placed.
+func (s *SystemStatus) CanTrade() bool {
+	return s.Status == SystemStatusOnline
+}
+
+// SubscriptionStatus is sent in response to a subscribe or unsubscribe.
+// Failed subscriptions are returned as a *SubscriptionError instead.
+type SubscriptionStatus struct {
+	ChannelID    int64  `json:"channelID"`
+	ChannelName  string `json:"channelName"`
+	Status       string `json:"status"`
+	Pair         string `json:"pair"`
+	RequestID    int64  `json:"reqid"`
+	ErrorMessage string `json:"errorMessage"`
+	Subscription struct {
+		Name     string   `json:"name"`
+		Depth    int      `json:"depth"`
+		Interval Interval `json:"interval"`
+	} `json:"subscription"`
+}
+
+// SubscriptionError is returned by Decode when a subscribe or unsubscribe
+// request fails.
+type SubscriptionError struct {
+	Name      string
+	Pair      string
+	RequestID int64
+	Message   string
+}
+
+func (e *SubscriptionError) Error() string {
+	if e.Pair != "" {
+		return fmt.Sprintf("subscription to %s for %s failed: %s",
+			e.Name, e.Pair, e.Message)
+	}
+	return fmt.Sprintf("subscription to %s failed: %s", e.Name, e.Message)
+}
+
+// Heartbeat is sent by the server when there is no other traffic.
+type Heartbeat struct{}
+
+// Pong is the response to a ping. Latency is only set for pings sent by a
+// LivenessMonitor.
+type Pong struct {
+	RequestID int64
+	Latency   time.Duration
+}
+
+func (s *WebSocket) decodeSubscriptionStatus(input []byte) (interface{}, error) {
+	var status SubscriptionStatus
+	if err := json.Unmarshal(input, &status); err != nil {
+		return nil, err
+	}
+	switch status.Status {
+	case "error":
+		return nil, &SubscriptionError{
+			Name:      status.Subscription.Name,
+			Pair:      status.Pair,
+			RequestID: status.RequestID,
+			Message:   status.ErrorMessage,
+		}
+	case "subscribed":
+		// Private feeds are identified by name and have no channel ID.
+		if status.ChannelName != "ownTrades" && status.ChannelName != "openOrders" {
+			s.channels[status.ChannelID] = channelMeta{
+				name:     status.Subscription.Name,
+				pair:     status.Pair,
+				depth:    status.Subscription.Depth,
+				interval: status.Subscription.Interval,
+			}
+		}
+	case "unsubscribed":
+		if meta, ok := s.channels[status.ChannelID]; ok {
+			if books := s.bookManager(); meta.name == "book" && books != nil {
+				books.Remove(meta.pair)
+			}
+			delete(s.channels, status.ChannelID)
+			s.forgetChannel(status.ChannelID)
+		}
+	}
+	return &status, nil
+}
+
+func (s *WebSocket) decodeSystemStatus(input []byte) (interface{}, error) {
+	var status SystemStatus
+	if err := json.Unmarshal(input, &status); err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	s.systemStatus = &status
+	s.lock.Unlock()
+	return &status, nil
+}
+
+// SystemStatus returns the last system status received, or nil if none
+// has been received yet.
+func (s *WebSocket) SystemStatus() *SystemStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.systemStatus
+}
@@ -0,0 +1,203 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"context"
+	"github.com/gorilla/websocket"
+	"time"
+)
+
+// StreamHandlers are the callbacks invoked by Stream for each decoded
+// event. Handlers are called from the read goroutine, one at a time. A nil
+// handler drops events of its type.
+type StreamHandlers struct {
+	OnTicker       func(*Ticker)
+	OnOHLC         func(*OHLC)
+	OnSpread       func(*Spread)
+	OnBookSnapshot func(*BookSnapshot)
+	OnBookUpdate   func(*BookUpdate)
+	OnTrade        func([]Trade)
+	OnOwnTrades    func(*OwnTrades)
+	OnOpenOrders   func(*OpenOrders)
+
+	// OnStatus is called with the status of the exchange on connect and
+	// whenever it changes.
+	OnStatus func(*SystemStatus)
+
+	// OnSubscription is called for subscription status events. Failed
+	// subscriptions are passed to OnError as a *SubscriptionError.
+	OnSubscription func(*SubscriptionStatus)
+
+	// OnError is called for messages that fail to decode and failed
+	// subscriptions. The stream continues after these errors.
+	OnError func(error)
+
+	// OnEvent is called for all other events, such as order statuses,
+	// book resyncs and the lifecycle events of a ManagedWebSocket.
+	OnEvent func(interface{})
+}
+
+func (h *StreamHandlers) dispatch(event interface{}) {
+	switch v := event.(type) {
+	case nil:
+	case *Ticker:
+		if h.OnTicker != nil {
+			h.OnTicker(v)
+		}
+	case *OHLC:
+		if h.OnOHLC != nil {
+			h.OnOHLC(v)
+		}
+	case *Spread:
+		if h.OnSpread != nil {
+			h.OnSpread(v)
+		}
+	case *BookSnapshot:
+		if h.OnBookSnapshot != nil {
+			h.OnBookSnapshot(v)
+		}
+	case *BookUpdate:
+		if h.OnBookUpdate != nil {
+			h.OnBookUpdate(v)
+		}
+	case []Trade:
+		if h.OnTrade != nil {
+			h.OnTrade(v)
+		}
+	case *OwnTrades:
+		if h.OnOwnTrades != nil {
+			h.OnOwnTrades(v)
+		}
+	case *OpenOrders:
+		if h.OnOpenOrders != nil {
+			h.OnOpenOrders(v)
+		}
+	case *SystemStatus:
+		if h.OnStatus != nil {
+			h.OnStatus(v)
+		}
+	case *SubscriptionStatus:
+		if h.OnSubscription != nil {
+			h.OnSubscription(v)
+		}
+	default:
+		if h.OnEvent != nil {
+			h.OnEvent(v)
+		}
+	}
+}
+
+func (h *StreamHandlers) error(err error) {
+	if h.OnError != nil {
+		h.OnError(err)
+	}
+}
+
+// Stream reads and decodes messages in a goroutine, passing each event to
+// the handlers. When the context is cancelled the connection is shut down
+// gracefully. The returned channel receives the error that ended the
+// stream, nil if ended by the context, and is then closed.
+func (s *WebSocket) Stream(ctx context.Context, handlers StreamHandlers) <-chan error {
+	result := make(chan error, 1)
+	finished := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Shutdown()
+		case <-finished:
+		}
+	}()
+
+	go func() {
+		defer close(result)
+		defer close(finished)
+		for {
+			payload, err := s.Next()
+			if err != nil {
+				if ctx.Err() != nil {
+					err = nil
+				}
+				result <- err
+				return
+			}
+			event, err := s.Decode(payload)
+			if err != nil {
+				handlers.error(err)
+				continue
+			}
+			handlers.dispatch(event)
+		}
+	}()
+
+	return result
+}
+
+// Shutdown sends a close message to the server before closing the
+// connection.
+func (s *WebSocket) Shutdown() error {
+	message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	s.Conn.WriteControl(websocket.CloseMessage, message,
+		time.Now().Add(time.Second))
+	return s.Close()
+}
+
+// Stream is the ManagedWebSocket equivalent of WebSocket.Stream. Lifecycle
+// events are passed to OnEvent. The stream only ends when the context is
+// cancelled or the ManagedWebSocket is closed.
+func (m *ManagedWebSocket) Stream(ctx context.Context, handlers StreamHandlers) <-chan error {
+	result := make(chan error, 1)
+	finished := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			m.Close()
+		case <-finished:
+		}
+	}()
+
+	go func() {
+		defer close(result)
+		defer close(finished)
+		for {
+			event, err := m.Next()
+			if err == ErrWebSocketClosed {
+				if ctx.Err() != nil {
+					err = nil
+				}
+				result <- err
+				return
+			}
+			if err != nil {
+				handlers.error(err)
+				continue
+			}
+			handlers.dispatch(event)
+		}
+	}()
+
+	return result
+}
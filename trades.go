@@ -0,0 +1,205 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import "strings"
+
+// TradeInfo is a trade of the authenticated user as returned by the REST
+// API.
+type TradeInfo struct {
+	TradeID   string            // Not in JSON response.
+	OrderTxID string            `json:"ordertxid"`
+	PosTxID   string            `json:"postxid"`
+	Pair      string            `json:"pair"`
+	Time      float64           `json:"time"`
+	Side      OrderSide         `json:"type"`
+	OrderType AddOrderOrderType `json:"ordertype"`
+	Price     Decimal           `json:"price"`
+	Cost      Decimal           `json:"cost"`
+	Fee       Decimal           `json:"fee"`
+	Volume    Decimal           `json:"vol"`
+	Margin    Decimal           `json:"margin"`
+	Misc      string            `json:"misc"`
+	Maker     bool              `json:"maker"`
+
+	// Only set for trades that opened a position.
+	PosStatus    string   `json:"posstatus"`
+	ClosedPrice  Decimal  `json:"cprice"`
+	ClosedCost   Decimal  `json:"ccost"`
+	ClosedFee    Decimal  `json:"cfee"`
+	ClosedVolume Decimal  `json:"cvol"`
+	ClosedMargin Decimal  `json:"cmargin"`
+	Net          Decimal  `json:"net"`
+	Trades       []string `json:"trades"`
+}
+
+func setTradeIDs(trades map[string]*TradeInfo) {
+	for id, trade := range trades {
+		trade.TradeID = id
+	}
+}
+
+// Values of TradesHistoryRequest.Type.
+const (
+	TradeTypeAll             = "all"
+	TradeTypeAnyPosition     = "any position"
+	TradeTypeClosedPosition  = "closed position"
+	TradeTypeClosingPosition = "closing position"
+	TradeTypeNoPosition      = "no position"
+)
+
+type TradesHistoryRequest struct {
+	// Type of trades, defaulting to all.
+	Type string
+
+	// Include the trades related to a position.
+	Trades bool
+
+	// Start and End are exclusive bounds, given as either a unix
+	// timestamp or a trade ID.
+	Start string
+	End   string
+
+	// Ofs is the offset of the first result, for paging.
+	Ofs int
+}
+
+type TradesHistoryResponse struct {
+	Error  []interface{} `json:"error"`
+	Result struct {
+		Trades map[string]*TradeInfo `json:"trades"`
+
+		// Count is the total number of trades matching the request.
+		Count int64 `json:"count"`
+	} `json:"result"`
+}
+
+func (r *TradesHistoryResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// TradesHistory gets trades, 50 at a time, most recent first.
+func (c *RestClient) TradesHistory(request TradesHistoryRequest) (*TradesHistoryResponse, error) {
+	params := map[string]interface{}{}
+	if request.Type != "" {
+		params["type"] = request.Type
+	}
+	if request.Trades {
+		params["trades"] = true
+	}
+	if request.Start != "" {
+		params["start"] = request.Start
+	}
+	if request.End != "" {
+		params["end"] = request.End
+	}
+	if request.Ofs > 0 {
+		params["ofs"] = request.Ofs
+	}
+	var response TradesHistoryResponse
+	if err := c.postAndDecode("/0/private/TradesHistory", params, &response); err != nil {
+		return nil, err
+	}
+	setTradeIDs(response.Result.Trades)
+	return &response, nil
+}
+
+type QueryTradesResponse struct {
+	Error  []interface{}         `json:"error"`
+	Result map[string]*TradeInfo `json:"result"`
+}
+
+func (r *QueryTradesResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// QueryTrades gets trades by ID. At most 20 IDs may be given.
+func (c *RestClient) QueryTrades(trades bool, txIds ...string) (*QueryTradesResponse, error) {
+	params := map[string]interface{}{
+		"txid": strings.Join(txIds, ","),
+	}
+	if trades {
+		params["trades"] = true
+	}
+	var response QueryTradesResponse
+	if err := c.postAndDecode("/0/private/QueryTrades", params, &response); err != nil {
+		return nil, err
+	}
+	setTradeIDs(response.Result)
+	return &response, nil
+}
+
+// Position is an open margin position.
+type Position struct {
+	PosTxID      string            // Not in JSON response.
+	OrderTxID    string            `json:"ordertxid"`
+	PosStatus    string            `json:"posstatus"`
+	Pair         string            `json:"pair"`
+	Time         float64           `json:"time"`
+	Side         OrderSide         `json:"type"`
+	OrderType    AddOrderOrderType `json:"ordertype"`
+	Cost         Decimal           `json:"cost"`
+	Fee          Decimal           `json:"fee"`
+	Volume       Decimal           `json:"vol"`
+	VolumeClosed Decimal           `json:"vol_closed"`
+	Margin       Decimal           `json:"margin"`
+	Terms        string            `json:"terms"`
+	RolloverTime float64           `json:"rollovertm,string"`
+	Misc         string            `json:"misc"`
+	OFlags       string            `json:"oflags"`
+
+	// Only set if calculations were requested.
+	Value Decimal `json:"value"`
+	Net   Decimal `json:"net"`
+}
+
+type OpenPositionsResponse struct {
+	Error  []interface{}        `json:"error"`
+	Result map[string]*Position `json:"result"`
+}
+
+func (r *OpenPositionsResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// OpenPositions gets open positions, optionally limited to the given
+// txids. If doCalcs is true the current value and net profit/loss are
+// calculated.
+func (c *RestClient) OpenPositions(doCalcs bool, txIds ...string) (*OpenPositionsResponse, error) {
+	params := map[string]interface{}{}
+	if len(txIds) > 0 {
+		params["txid"] = strings.Join(txIds, ",")
+	}
+	if doCalcs {
+		params["docalcs"] = true
+	}
+	var response OpenPositionsResponse
+	if err := c.postAndDecode("/0/private/OpenPositions", params, &response); err != nil {
+		return nil, err
+	}
+	for txid, position := range response.Result {
+		position.PosTxID = txid
+	}
+	return &response, nil
+}
@@ -0,0 +1,176 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FeeTierInfo is the fee tier of a pair, with fees as a percentage.
+type FeeTierInfo struct {
+	Fee    Decimal `json:"fee"`
+	MinFee Decimal `json:"minfee"`
+	MaxFee Decimal `json:"maxfee"`
+
+	// NextFee and NextVolume are 0 when already in the lowest tier.
+	NextFee    Decimal `json:"nextfee"`
+	NextVolume Decimal `json:"nextvolume"`
+	TierVolume Decimal `json:"tiervolume"`
+}
+
+type TradeVolumeResponse struct {
+	Error  []interface{} `json:"error"`
+	Result struct {
+		Currency string  `json:"currency"`
+		Volume   Decimal `json:"volume"`
+
+		// Taker and maker fees keyed by the pair name used by the REST
+		// API.
+		Fees      map[string]FeeTierInfo `json:"fees"`
+		FeesMaker map[string]FeeTierInfo `json:"fees_maker"`
+	} `json:"result"`
+}
+
+func (r *TradeVolumeResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// FeeSchedule returns the fees of the response as a FeeSchedule.
+func (r *TradeVolumeResponse) FeeSchedule() *FeeSchedule {
+	schedule := &FeeSchedule{
+		Taker: map[string]Decimal{},
+		Maker: map[string]Decimal{},
+	}
+	for pair, info := range r.Result.Fees {
+		schedule.Taker[pair] = info.Fee
+	}
+	for pair, info := range r.Result.FeesMaker {
+		schedule.Maker[pair] = info.Fee
+	}
+	return schedule
+}
+
+// TradeVolume gets the 30 day trade volume and, if feeInfo is true, the
+// current fee tier of each pair.
+func (c *RestClient) TradeVolume(feeInfo bool, pairs ...string) (*TradeVolumeResponse, error) {
+	params := map[string]interface{}{}
+	if len(pairs) > 0 {
+		params["pair"] = strings.Join(pairs, ",")
+	}
+	if feeInfo {
+		params["fee-info"] = true
+	}
+	var response TradeVolumeResponse
+	if err := c.postAndDecode("/0/private/TradeVolume", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// FeeSchedule holds the taker and maker fee of each pair, as a percentage.
+type FeeSchedule struct {
+	Taker map[string]Decimal
+	Maker map[string]Decimal
+}
+
+// Fees returns the taker and maker fee of a pair. Pairs without a separate
+// maker fee use the taker fee for both.
+func (s *FeeSchedule) Fees(pair string) (taker Decimal, maker Decimal, ok bool) {
+	for _, key := range []string{pair, RestPair(pair)} {
+		if taker, ok = s.Taker[key]; ok {
+			if maker, ok = s.Maker[key]; !ok {
+				maker = taker
+			}
+			return taker, maker, true
+		}
+	}
+	return Decimal{}, Decimal{}, false
+}
+
+// EstimateFee returns the expected fee of an order in the quote currency.
+// The market price is the current price of the pair, used to price orders
+// without an absolute price and to tell whether a limit order would cross
+// the book. With a zero market price, limit orders are assumed to rest on
+// the book.
+//
+// Post-only orders pay the maker fee. IOC and marketable limit orders pay
+// the taker fee, as do market, stop, take-profit and trailing stop orders,
+// including their limit variants, which are assumed to fill at their
+// absolute trigger price, or the market price for trailing stops.
+func (s *FeeSchedule) EstimateFee(order AddOrderRequest, market Decimal) (Decimal, error) {
+	taker, maker, ok := s.Fees(order.Pair)
+	if !ok {
+		return Decimal{}, fmt.Errorf("no fees for pair: %s", order.Pair)
+	}
+
+	fee := taker
+	if order.isMaker(market) {
+		fee = maker
+	}
+
+	cost := order.Volume
+	if !order.hasFlag(OrderFlagVolumeInQuote) {
+		price := market
+		if order.PriceOffset == PriceAbsolute && !order.PricePercent &&
+			order.Type.usesPrice() {
+			price = order.Price
+		}
+		if price.Sign() <= 0 {
+			return Decimal{}, fmt.Errorf("a price is required to estimate the fee")
+		}
+		cost = price.Mul(order.Volume)
+	}
+
+	// Fees are a percentage.
+	return cost.Mul(fee).Mul(NewDecimal(1, 2)), nil
+}
+
+// isMaker returns true if the order is expected to rest on the book given
+// the market price, which may be zero if unknown.
+func (r AddOrderRequest) isMaker(market Decimal) bool {
+	if r.hasFlag(OrderFlagPost) {
+		return true
+	}
+	if r.Type != OrderTypeLimit || r.TimeInForce == TimeInForceIOC {
+		return false
+	}
+	// Offsets are from the market price; # moves the price toward the
+	// other side of the book.
+	switch r.PriceOffset {
+	case PriceOffsetPlus:
+		return r.Side == OrderSideSell
+	case PriceOffsetMinus:
+		return r.Side == OrderSideBuy
+	case PriceOffsetSide:
+		return false
+	}
+	if market.IsZero() {
+		return true
+	}
+	if r.Side == OrderSideBuy {
+		return r.Price.LessThan(market)
+	}
+	return r.Price.GreaterThan(market)
+}
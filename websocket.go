@@ -31,22 +31,75 @@ import (
 	"github.com/gorilla/websocket"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 )
 
 var WS_URL = "wss://ws.kraken.com"
+var WS_AUTH_URL = "wss://ws-auth.kraken.com"
 
 type channelMeta struct {
-	name string
-	pair string
+	name     string
+	pair     string
+	depth    int
+	interval Interval
 }
 
+// WebSocket is a connection to the Kraken websocket API. Its methods may be
+// called concurrently, but messages must be read from a single goroutine.
 type WebSocket struct {
+	// Conn is the underlying connection. Writing to it directly bypasses
+	// the serialization of writes done by WebSocket.
 	Conn     *websocket.Conn
 	channels map[int64]channelMeta
+
+	// Book manager, guarded by lock.
+	books *BookManager
+
+	// Token for private feeds, only set on a private WebSocket.
+	token string
+
+	// Requests waiting on a response, keyed by reqid.
+	lock    sync.Mutex
+	reqID   int64
+	pending map[int64]pendingRequest
+
+	// Liveness tracking, guarded by lock.
+	lastMessage   time.Time
+	lastHeartbeat time.Time
+	channelSeen   map[int64]time.Time
+	pings         map[int64]time.Time
+	latency       time.Duration
+	systemStatus  *SystemStatus
+
+	// Settings used by AddOrder to round to the precision of a pair,
+	// guarded by lock.
+	rounding orderRounding
+
+	writeLock sync.Mutex
 }
 
 func OpenWebSocket() (*WebSocket, error) {
-	conn, response, err := websocket.DefaultDialer.Dial(WS_URL, nil)
+	return openWebSocket(WS_URL)
+}
+
+// OpenPrivateWebSocket opens a WebSocket to the authenticated endpoint
+// for private feeds. The token is obtained with
+// RestClient.GetWebSocketsToken.
+func OpenPrivateWebSocket(token string) (*WebSocket, error) {
+	if token == "" {
+		return nil, fmt.Errorf("a token is required for a private websocket")
+	}
+	ws, err := openWebSocket(WS_AUTH_URL)
+	if err != nil {
+		return nil, err
+	}
+	ws.token = token
+	return ws, nil
+}
+
+func openWebSocket(url string) (*WebSocket, error) {
+	conn, response, err := websocket.DefaultDialer.Dial(url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +109,7 @@ func OpenWebSocket() (*WebSocket, error) {
 	return &WebSocket{
 		Conn:     conn,
 		channels: map[int64]channelMeta{},
+		pending:  map[int64]pendingRequest{},
 	}, nil
 }
 
@@ -65,28 +119,52 @@ func (s *WebSocket) Decode(input []byte) (interface{}, error) {
 		return nil, nil
 	}
 
+	s.touch()
+
 	if input[0] == '{' {
 		// Attempt to decode as a EventMessage.
 		var status EventMessage
 		if err := json.Unmarshal(input, &status); err != nil {
 			return nil, err
-		} else {
-			// TODO: Handler status.ErrorMessage.
-			if status.Event == "subscriptionStatus" {
-				s.channels[status.ChannelID] = channelMeta{
-					name: status.Subscription.Name,
-					pair: status.Pair,
-				}
-			}
-			return status, nil
 		}
+		switch status.Event {
+		case "addOrderStatus", "cancelOrderStatus", "cancelAllStatus":
+			return s.resolveRequest(status.Event, status.RequestID, input)
+		case "subscriptionStatus":
+			return s.decodeSubscriptionStatus(input)
+		case "systemStatus":
+			return s.decodeSystemStatus(input)
+		case "heartbeat":
+			s.touchHeartbeat()
+			return &Heartbeat{}, nil
+		case "pong":
+			return &Pong{
+				RequestID: status.RequestID,
+				Latency:   s.touchPong(status.RequestID),
+			}, nil
+		}
+		return status, nil
 	} else if input[0] == '[' {
 		decoded, err := decodeArray(input)
 		if err != nil {
 			return nil, err
 		}
 
-		channel, err := decoded[0].(json.Number).Int64()
+		// Private feeds are identified by the channel name in the second
+		// element instead of a channel ID in the first.
+		if len(decoded) > 1 {
+			if name, ok := decoded[1].(string); ok {
+				if _, ok := decoded[0].([]interface{}); ok {
+					return decodePrivate(name, decoded)
+				}
+			}
+		}
+
+		number, ok := decoded[0].(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("invalid channel id: %v", decoded[0])
+		}
+		channel, err := number.Int64()
 		if err != nil {
 			return nil, fmt.Errorf("failed to decode channel id: %+v", err)
 		}
@@ -95,6 +173,7 @@ func (s *WebSocket) Decode(input []byte) (interface{}, error) {
 		if !ok {
 			return nil, fmt.Errorf("failed to find type for channel: %d", channel)
 		}
+		s.touchChannel(channel)
 
 		switch meta.name {
 		case "ticker":
@@ -132,6 +211,51 @@ func (s *WebSocket) Decode(input []byte) (interface{}, error) {
 			} else {
 				return nil, fmt.Errorf("invalid type for spread event")
 			}
+		case "trade":
+			if v, ok := decoded[1].([]interface{}); ok {
+				trades, err := DecodeTrades(v)
+				if err != nil {
+					return nil, err
+				}
+				for i := range trades {
+					trades[i].Pair = meta.pair
+				}
+				return trades, nil
+			} else {
+				return nil, fmt.Errorf("invalid type for trade event")
+			}
+		case "book":
+			if len(decoded) < 4 {
+				return nil, fmt.Errorf("not enough items in book event")
+			}
+			book, err := DecodeBook(decoded[1 : len(decoded)-2])
+			if err != nil {
+				return nil, err
+			}
+			switch v := book.(type) {
+			case *BookSnapshot:
+				v.Pair = meta.pair
+				v.Depth = meta.depth
+			case *BookUpdate:
+				v.Pair = meta.pair
+				v.Depth = meta.depth
+			}
+			if books := s.bookManager(); books != nil {
+				if err := books.Apply(book); err != nil {
+					if _, ok := err.(*BookChecksumError); ok &&
+						books.ChecksumPolicy() == ChecksumPolicyResync {
+						if err := s.resyncBook(meta); err != nil {
+							return nil, err
+						}
+						return &BookResync{
+							Pair:  meta.pair,
+							Depth: meta.depth,
+						}, nil
+					}
+					return nil, err
+				}
+			}
+			return book, nil
 		default:
 			return nil, fmt.Errorf("unknown channel type: %s", meta.name)
 		}
@@ -146,7 +270,8 @@ func (s *WebSocket) Next() ([]byte, error) {
 }
 
 // Ping sends an application ping to the server. The reqId will only be
-// included if non-zero.
+// included if non-zero. The latency of pings sent with Ping is not
+// measured; use MonitorLiveness for that.
 func (s *WebSocket) Ping(reqId int) error {
 	ping := map[string]interface{}{
 		"event": "ping",
@@ -154,7 +279,22 @@ func (s *WebSocket) Ping(reqId int) error {
 	if reqId > 0 {
 		ping["reqid"] = reqId
 	}
-	return s.Conn.WriteJSON(ping)
+	return s.writeJSON(ping)
+}
+
+// subscribe sends a subscribe or unsubscribe message with a new reqid, so
+// a failed subscription can be matched to its request.
+func (s *WebSocket) subscribe(message SubscribeMessage) error {
+	message.RequestID = s.nextRequestID()
+	return s.writeJSON(&message)
+}
+
+// writeJSON serializes writes to the connection, which does not support
+// concurrent writers.
+func (s *WebSocket) writeJSON(v interface{}) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	return s.Conn.WriteJSON(v)
 }
 
 func (s *WebSocket) SubscribeTicker(tickers ...string) error {
@@ -165,7 +305,7 @@ func (s *WebSocket) SubscribeTicker(tickers ...string) error {
 			"name": "ticker",
 		},
 	}
-	return s.Conn.WriteJSON(&message)
+	return s.subscribe(message)
 }
 
 func (s *WebSocket) SubscribeBook(ticker string) error {
@@ -176,7 +316,48 @@ func (s *WebSocket) SubscribeBook(ticker string) error {
 			"name": "book",
 		},
 	}
-	return s.Conn.WriteJSON(&message)
+	return s.subscribe(message)
+}
+
+// SubscribeBookDepth subscribes to the book with the given depth. Valid
+// depths are 10, 25, 100, 500 and 1000.
+func (s *WebSocket) SubscribeBookDepth(depth int, tickers ...string) error {
+	message := SubscribeMessage{
+		Event: "subscribe",
+		Pair:  tickers,
+		Subscription: map[string]interface{}{
+			"name":  "book",
+			"depth": depth,
+		},
+	}
+	return s.subscribe(message)
+}
+
+// SetBookManager attaches a BookManager that will have all book snapshots
+// and updates decoded by this WebSocket applied to it. It is safe to call
+// while messages are being decoded.
+func (s *WebSocket) SetBookManager(books *BookManager) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.books = books
+}
+
+func (s *WebSocket) bookManager() *BookManager {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.books
+}
+
+// resyncBook unsubscribes and resubscribes to a book to get a fresh
+// snapshot after a checksum mismatch.
+func (s *WebSocket) resyncBook(meta channelMeta) error {
+	if err := s.UnsubscribeBook(meta.depth, meta.pair); err != nil {
+		return err
+	}
+	if meta.depth > 0 {
+		return s.SubscribeBookDepth(meta.depth, meta.pair)
+	}
+	return s.SubscribeBook(meta.pair)
 }
 
 // Interval is a set of constants for OHLC intervals.
@@ -203,7 +384,7 @@ func (s *WebSocket) SubscribeOHLC(interval Interval, tickers ...string) error {
 			"interval": interval,
 		},
 	}
-	return s.Conn.WriteJSON(message)
+	return s.subscribe(message)
 }
 
 func (s *WebSocket) SubscribeSpread(tickers ...string) error {
@@ -214,10 +395,78 @@ func (s *WebSocket) SubscribeSpread(tickers ...string) error {
 			"name": "spread",
 		},
 	}
-	return s.Conn.WriteJSON(message)
+	return s.subscribe(message)
+}
+
+func (s *WebSocket) SubscribeTrade(tickers ...string) error {
+	message := SubscribeMessage{
+		Event: "subscribe",
+		Pair:  tickers,
+		Subscription: map[string]interface{}{
+			"name": "trade",
+		},
+	}
+	return s.subscribe(message)
+}
+
+func (s *WebSocket) unsubscribe(tickers []string, subscription map[string]interface{}) error {
+	message := SubscribeMessage{
+		Event:        "unsubscribe",
+		Pair:         tickers,
+		Subscription: subscription,
+	}
+	return s.subscribe(message)
+}
+
+func (s *WebSocket) UnsubscribeTicker(tickers ...string) error {
+	return s.unsubscribe(tickers, map[string]interface{}{
+		"name": "ticker",
+	})
+}
+
+func (s *WebSocket) UnsubscribeOHLC(interval Interval, tickers ...string) error {
+	return s.unsubscribe(tickers, map[string]interface{}{
+		"name":     "ohlc",
+		"interval": interval,
+	})
+}
+
+func (s *WebSocket) UnsubscribeSpread(tickers ...string) error {
+	return s.unsubscribe(tickers, map[string]interface{}{
+		"name": "spread",
+	})
+}
+
+// UnsubscribeBook unsubscribes from the book. A depth of 0 matches a
+// subscription made without a depth.
+func (s *WebSocket) UnsubscribeBook(depth int, tickers ...string) error {
+	subscription := map[string]interface{}{
+		"name": "book",
+	}
+	if depth > 0 {
+		subscription["depth"] = depth
+	}
+	return s.unsubscribe(tickers, subscription)
+}
+
+func (s *WebSocket) UnsubscribeTrade(tickers ...string) error {
+	return s.unsubscribe(tickers, map[string]interface{}{
+		"name": "trade",
+	})
+}
+
+// UnsubscribeChannel unsubscribes from a channel by its channel ID.
+func (s *WebSocket) UnsubscribeChannel(channelID int64) error {
+	message := UnsubscribeChannelMessage{
+		Event:     "unsubscribe",
+		RequestID: s.nextRequestID(),
+		ChannelID: channelID,
+	}
+	return s.writeJSON(message)
 }
 
 func (s *WebSocket) Close() error {
+	s.abortRequests()
 	return s.Conn.Close()
 }
 
@@ -229,50 +478,59 @@ type EventMessage struct {
 	ErrorMessage string `json:"errorMessage"`
 	RequestID    int64  `json:"reqid"`
 	Subscription struct {
-		Name string `json:"name"`
+		Name     string   `json:"name"`
+		Depth    int      `json:"depth"`
+		Interval Interval `json:"interval"`
 	}
 }
 
 type SubscribeMessage struct {
 	Event        string                 `json:"event"`
-	Pair         []string               `json:"pair"`
+	RequestID    int64                  `json:"reqid,omitempty"`
+	Pair         []string               `json:"pair,omitempty"`
 	Subscription map[string]interface{} `json:"subscription"`
 }
 
+type UnsubscribeChannelMessage struct {
+	Event     string `json:"event"`
+	RequestID int64  `json:"reqid,omitempty"`
+	ChannelID int64  `json:"channelID"`
+}
+
 // Ticker is the decoded representation of a ticker.
 type Ticker struct {
 	Pair string
 
 	// Ask.
 	Ask struct {
-		Price          float64
+		Price          Decimal
 		WholeLotVolume int64
-		LotVolume      float64
+		LotVolume      Decimal
 	}
 
 	// Bid.
 	Bid struct {
-		Price          float64
+		Price          Decimal
 		WholeLotVolume int64
-		LotVolume      float64
+		LotVolume      Decimal
 	}
 
 	// Close.
 	Close struct {
-		Price     float64
-		LotVolume float64
+		Price     Decimal
+		LotVolume Decimal
 	}
 
 	// Volume.
 	Volume struct {
-		Today       float64
-		Last24Hours float64
+		Today       Decimal
+		Last24Hours Decimal
 	}
 
 	// VWAP.
 	Vwap struct {
-		Today       float64
-		Last24Hours float64
+		Today       Decimal
+		Last24Hours Decimal
 	}
 
 	// Number of trades.
@@ -283,20 +541,20 @@ type Ticker struct {
 
 	// Low price.
 	Low struct {
-		Today       float64
-		Last24Hours float64
+		Today       Decimal
+		Last24Hours Decimal
 	}
 
 	// High price.
 	High struct {
-		Today       float64
-		Last24Hours float64
+		Today       Decimal
+		Last24Hours Decimal
 	}
 
 	// Open price.
 	Open struct {
-		Today       float64
-		Last24Hours float64
+		Today       Decimal
+		Last24Hours Decimal
 	}
 }
 
@@ -320,13 +578,13 @@ func DecodeTicker(data map[string]interface{}) (*Ticker, error) {
 	if len(ask) < 3 {
 		return nil, fmt.Errorf("not enough values in ask")
 	}
-	if ticker.Ask.Price, err = parseFloat(ask[0]); err != nil {
+	if ticker.Ask.Price, err = parseDecimal(ask[0]); err != nil {
 		return nil, err
 	}
-	if ticker.Ask.WholeLotVolume, err = ask[1].(json.Number).Int64(); err != nil {
+	if ticker.Ask.WholeLotVolume, err = parseInt(ask[1]); err != nil {
 		return nil, err
 	}
-	if ticker.Ask.LotVolume, err = parseFloat(ask[2]); err != nil {
+	if ticker.Ask.LotVolume, err = parseDecimal(ask[2]); err != nil {
 		return nil, err
 	}
 
@@ -338,13 +596,13 @@ func DecodeTicker(data map[string]interface{}) (*Ticker, error) {
 	if len(bid) < 3 {
 		return nil, fmt.Errorf("not enough values in bid")
 	}
-	if ticker.Bid.Price, err = parseFloat(bid[0]); err != nil {
+	if ticker.Bid.Price, err = parseDecimal(bid[0]); err != nil {
 		return nil, err
 	}
-	if ticker.Bid.WholeLotVolume, err = bid[1].(json.Number).Int64(); err != nil {
+	if ticker.Bid.WholeLotVolume, err = parseInt(bid[1]); err != nil {
 		return nil, err
 	}
-	if ticker.Bid.LotVolume, err = parseFloat(bid[2]); err != nil {
+	if ticker.Bid.LotVolume, err = parseDecimal(bid[2]); err != nil {
 		return nil, err
 	}
 
@@ -353,7 +611,7 @@ func DecodeTicker(data map[string]interface{}) (*Ticker, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid close")
 	}
-	if ticker.Close.Price, ticker.Close.LotVolume, err = parseFloatDouble(xclose); err != nil {
+	if ticker.Close.Price, ticker.Close.LotVolume, err = parseDecimalDouble(xclose); err != nil {
 		return nil, err
 	}
 
@@ -362,7 +620,7 @@ func DecodeTicker(data map[string]interface{}) (*Ticker, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid volume")
 	}
-	if ticker.Volume.Today, ticker.Volume.Last24Hours, err = parseFloatDouble(volume); err != nil {
+	if ticker.Volume.Today, ticker.Volume.Last24Hours, err = parseDecimalDouble(volume); err != nil {
 		return nil, err
 	}
 
@@ -371,7 +629,7 @@ func DecodeTicker(data map[string]interface{}) (*Ticker, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid vwap")
 	}
-	if ticker.Vwap.Today, ticker.Vwap.Last24Hours, err = parseFloatDouble(vwap); err != nil {
+	if ticker.Vwap.Today, ticker.Vwap.Last24Hours, err = parseDecimalDouble(vwap); err != nil {
 		return nil, err
 	}
 
@@ -380,10 +638,13 @@ func DecodeTicker(data map[string]interface{}) (*Ticker, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid trades")
 	}
-	if ticker.Trades.Today, err = trades[0].(json.Number).Int64(); err != nil {
+	if len(trades) < 2 {
+		return nil, fmt.Errorf("not enough values in trades")
+	}
+	if ticker.Trades.Today, err = parseInt(trades[0]); err != nil {
 		return nil, err
 	}
-	if ticker.Trades.Last24Hours, err = trades[1].(json.Number).Int64(); err != nil {
+	if ticker.Trades.Last24Hours, err = parseInt(trades[1]); err != nil {
 		return nil, err
 	}
 
@@ -392,7 +653,7 @@ func DecodeTicker(data map[string]interface{}) (*Ticker, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid low price")
 	}
-	if ticker.Low.Today, ticker.Low.Last24Hours, err = parseFloatDouble(low); err != nil {
+	if ticker.Low.Today, ticker.Low.Last24Hours, err = parseDecimalDouble(low); err != nil {
 		return nil, err
 	}
 
@@ -401,18 +662,24 @@ func DecodeTicker(data map[string]interface{}) (*Ticker, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid high price")
 	}
-	if ticker.High.Today, ticker.High.Last24Hours, err = parseFloatDouble(high); err != nil {
+	if ticker.High.Today, ticker.High.Last24Hours, err = parseDecimalDouble(high); err != nil {
 		return nil, err
 	}
 
-	// Open price.
-	open, ok := data["o"].([]interface{})
-	if !ok {
+	// Open price. The REST API only provides today's open as a single
+	// value.
+	switch open := data["o"].(type) {
+	case []interface{}:
+		if ticker.Open.Today, ticker.Open.Last24Hours, err = parseDecimalDouble(open); err != nil {
+			return nil, err
+		}
+	case string:
+		if ticker.Open.Today, err = parseDecimal(open); err != nil {
+			return nil, err
+		}
+	default:
 		return nil, fmt.Errorf("invalid open price")
 	}
-	if ticker.Open.Today, ticker.Open.Last24Hours, err = parseFloatDouble(open); err != nil {
-		return nil, err
-	}
 
 	return ticker, nil
 }
@@ -422,12 +689,12 @@ type OHLC struct {
 	Pair    string
 	Time    float64
 	EndTime float64
-	Open    float64
-	High    float64
-	Low     float64
-	Close   float64
-	VWAP    float64
-	Volume  float64
+	Open    Decimal
+	High    Decimal
+	Low     Decimal
+	Close   Decimal
+	VWAP    Decimal
+	Volume  Decimal
 	Count   int64
 }
 
@@ -441,22 +708,22 @@ func DecodeOHLC(data []interface{}) (*OHLC, error) {
 	if ohlc.EndTime, err = parseFloat(data[1]); err != nil {
 		return nil, err
 	}
-	if ohlc.Open, err = parseFloat(data[2]); err != nil {
+	if ohlc.Open, err = parseDecimal(data[2]); err != nil {
 		return nil, err
 	}
-	if ohlc.High, err = parseFloat(data[3]); err != nil {
+	if ohlc.High, err = parseDecimal(data[3]); err != nil {
 		return nil, err
 	}
-	if ohlc.Low, err = parseFloat(data[4]); err != nil {
+	if ohlc.Low, err = parseDecimal(data[4]); err != nil {
 		return nil, err
 	}
-	if ohlc.Close, err = parseFloat(data[5]); err != nil {
+	if ohlc.Close, err = parseDecimal(data[5]); err != nil {
 		return nil, err
 	}
-	if ohlc.VWAP, err = parseFloat(data[6]); err != nil {
+	if ohlc.VWAP, err = parseDecimal(data[6]); err != nil {
 		return nil, err
 	}
-	if ohlc.Volume, err = parseFloat(data[7]); err != nil {
+	if ohlc.Volume, err = parseDecimal(data[7]); err != nil {
 		return nil, err
 	}
 	if ohlc.Count, err = data[8].(json.Number).Int64(); err != nil {
@@ -468,8 +735,8 @@ func DecodeOHLC(data []interface{}) (*OHLC, error) {
 // Spread represents a decoded spread event for a pair.
 type Spread struct {
 	Pair      string
-	Bid       float64
-	Ask       float64
+	Bid       Decimal
+	Ask       Decimal
 	Timestamp float64
 }
 
@@ -480,10 +747,10 @@ func DecodeSpread(input []interface{}) (*Spread, error) {
 	if len(input) < 3 {
 		return nil, fmt.Errorf("not enough items")
 	}
-	if spread.Bid, err = parseFloat(input[0]); err != nil {
+	if spread.Bid, err = parseDecimal(input[0]); err != nil {
 		return nil, err
 	}
-	if spread.Ask, err = parseFloat(input[1]); err != nil {
+	if spread.Ask, err = parseDecimal(input[1]); err != nil {
 		return nil, err
 	}
 	if spread.Timestamp, err = parseFloat(input[2]); err != nil {
@@ -492,7 +759,75 @@ func DecodeSpread(input []interface{}) (*Spread, error) {
 	return spread, nil
 }
 
+// Trade represents a single trade on a pair.
+type Trade struct {
+	Pair      string
+	Price     Decimal
+	Volume    Decimal
+	Time      float64
+	Side      OrderSide
+	OrderType AddOrderOrderType
+	Misc      string
+}
+
+// DecodeTrade decodes an array into a Trade.
+func DecodeTrade(input []interface{}) (*Trade, error) {
+	var err error = nil
+	var trade *Trade = &Trade{}
+	if len(input) < 6 {
+		return nil, fmt.Errorf("not enough items")
+	}
+	if trade.Price, err = parseDecimal(input[0]); err != nil {
+		return nil, err
+	}
+	if trade.Volume, err = parseDecimal(input[1]); err != nil {
+		return nil, err
+	}
+	if trade.Time, err = parseFloat(input[2]); err != nil {
+		return nil, err
+	}
+	switch input[3] {
+	case "b":
+		trade.Side = OrderSideBuy
+	case "s":
+		trade.Side = OrderSideSell
+	default:
+		return nil, fmt.Errorf("invalid trade side: %v", input[3])
+	}
+	switch input[4] {
+	case "l":
+		trade.OrderType = OrderTypeLimit
+	case "m":
+		trade.OrderType = OrderTypeMarket
+	default:
+		return nil, fmt.Errorf("invalid trade order type: %v", input[4])
+	}
+	trade.Misc, _ = input[5].(string)
+	return trade, nil
+}
+
+// DecodeTrades decodes an array of trade arrays.
+func DecodeTrades(input []interface{}) ([]Trade, error) {
+	trades := make([]Trade, 0, len(input))
+	for _, item := range input {
+		values, ok := item.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid type for trade")
+		}
+		trade, err := DecodeTrade(values)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, *trade)
+	}
+	return trades, nil
+}
+
 func parseFloat(input interface{}) (float64, error) {
+	// Timestamps in REST responses are numbers.
+	if number, ok := input.(json.Number); ok {
+		return number.Float64()
+	}
 	value, ok := input.(string)
 	if !ok {
 		return 0, fmt.Errorf("parseFloat: input not a string: %+v", input)
@@ -500,17 +835,41 @@ func parseFloat(input interface{}) (float64, error) {
 	return strconv.ParseFloat(value, 64)
 }
 
-func parseFloatDouble(input []interface{}) (float64, float64, error) {
+// parseDecimal parses a decimal from a string or, in REST responses, a
+// number.
+func parseDecimal(input interface{}) (Decimal, error) {
+	switch value := input.(type) {
+	case json.Number:
+		return ParseDecimal(value.String())
+	case string:
+		return ParseDecimal(value)
+	default:
+		return Decimal{}, fmt.Errorf("parseDecimal: input not a number: %+v", input)
+	}
+}
+
+func parseInt(input interface{}) (int64, error) {
+	switch value := input.(type) {
+	case json.Number:
+		return value.Int64()
+	case string:
+		return strconv.ParseInt(value, 10, 64)
+	default:
+		return 0, fmt.Errorf("parseInt: input not a number: %+v", input)
+	}
+}
+
+func parseDecimalDouble(input []interface{}) (Decimal, Decimal, error) {
 	if len(input) != 2 {
-		return 0, 0, fmt.Errorf("parseFloatDouble: invalid number of elements")
+		return Decimal{}, Decimal{}, fmt.Errorf("parseDecimalDouble: invalid number of elements")
 	}
-	a, err := parseFloat(input[0])
+	a, err := parseDecimal(input[0])
 	if err != nil {
-		return 0, 0, err
+		return Decimal{}, Decimal{}, err
 	}
-	b, err := parseFloat(input[1])
+	b, err := parseDecimal(input[1])
 	if err != nil {
-		return 0, 0, err
+		return Decimal{}, Decimal{}, err
 	}
 	return a, b, nil
 }
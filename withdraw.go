@@ -0,0 +1,156 @@
+// MIT License
+//
+// Copyright (c) 2019 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package krakenapi
+
+import (
+	"fmt"
+)
+
+// SetWithdrawKeyAllowlist restricts Withdraw to the given withdrawal keys.
+// Once set, Withdraw refuses to send a request for any other key. Calling
+// it with no keys blocks all withdrawals.
+func (c *RestClient) SetWithdrawKeyAllowlist(keys ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.withdrawKeys = map[string]bool{}
+	for _, key := range keys {
+		c.withdrawKeys[key] = true
+	}
+}
+
+func (c *RestClient) withdrawKeyAllowed(key string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.withdrawKeys == nil || c.withdrawKeys[key]
+}
+
+type WithdrawInfoResponse struct {
+	Error  []interface{} `json:"error"`
+	Result struct {
+		Method string  `json:"method"`
+		Limit  Decimal `json:"limit"`
+		Amount Decimal `json:"amount"`
+		Fee    Decimal `json:"fee"`
+	} `json:"result"`
+}
+
+func (r *WithdrawInfoResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// WithdrawInfo gets the method, limit and fee of withdrawing amount of an
+// asset to the withdrawal key, which is the name of the destination as
+// set up in the account.
+func (c *RestClient) WithdrawInfo(asset string, key string, amount Decimal) (*WithdrawInfoResponse, error) {
+	params := map[string]interface{}{
+		"asset":  asset,
+		"key":    key,
+		"amount": amount.String(),
+	}
+	var response WithdrawInfoResponse
+	if err := c.postAndDecode("/0/private/WithdrawInfo", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+type WithdrawResponse struct {
+	Error  []interface{} `json:"error"`
+	Result struct {
+		RefID string `json:"refid"`
+	} `json:"result"`
+}
+
+func (r *WithdrawResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// Withdraw withdraws amount of an asset to the withdrawal key. If an
+// allowlist has been set with SetWithdrawKeyAllowlist, keys not on it are
+// refused without sending a request.
+func (c *RestClient) Withdraw(asset string, key string, amount Decimal) (*WithdrawResponse, error) {
+	if !c.withdrawKeyAllowed(key) {
+		return nil, fmt.Errorf("withdrawal key not in allowlist: %s", key)
+	}
+	params := map[string]interface{}{
+		"asset":  asset,
+		"key":    key,
+		"amount": amount.String(),
+	}
+	var response WithdrawResponse
+	if err := c.postAndDecode("/0/private/Withdraw", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+type WithdrawStatusResponse struct {
+	Error  []interface{}   `json:"error"`
+	Result []FundingStatus `json:"result"`
+}
+
+func (r *WithdrawStatusResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// WithdrawStatus gets the status of recent withdrawals of an asset. If
+// method is empty withdrawals of all methods are returned.
+func (c *RestClient) WithdrawStatus(asset string, method string) (*WithdrawStatusResponse, error) {
+	params := map[string]interface{}{
+		"asset": asset,
+	}
+	if method != "" {
+		params["method"] = method
+	}
+	var response WithdrawStatusResponse
+	if err := c.postAndDecode("/0/private/WithdrawStatus", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+type WithdrawCancelResponse struct {
+	Error []interface{} `json:"error"`
+
+	// Result is true if the cancellation was successful. A withdrawal
+	// can only be cancelled before it has been processed.
+	Result bool `json:"result"`
+}
+
+func (r *WithdrawCancelResponse) HasError() bool {
+	return len(r.Error) > 0
+}
+
+// WithdrawCancel requests cancellation of a withdrawal by its refid.
+func (c *RestClient) WithdrawCancel(asset string, refId string) (*WithdrawCancelResponse, error) {
+	params := map[string]interface{}{
+		"asset": asset,
+		"refid": refId,
+	}
+	var response WithdrawCancelResponse
+	if err := c.postAndDecode("/0/private/WithdrawCancel", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}